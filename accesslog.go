@@ -0,0 +1,137 @@
+package httpwrap
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// _redacted replaces the values of redacted headers and query parameters.
+const _redacted = "REDACTED"
+
+// AccessLogOption configures the access logs emitted by LogRequests.
+type AccessLogOption func(*accessLogConfig)
+
+type accessLogConfig struct {
+	headers       []string
+	redactHeaders map[string]bool
+	redactQuery   map[string]bool
+}
+
+// LogHeaders adds the given request headers to the access logs.
+func LogHeaders(names ...string) AccessLogOption {
+	return func(config *accessLogConfig) {
+		config.headers = append(config.headers, names...)
+	}
+}
+
+// RedactHeaders replaces the values of the given request headers in the
+// access logs. The Authorization, Proxy-Authorization and Cookie headers are
+// always redacted.
+func RedactHeaders(names ...string) AccessLogOption {
+	return func(config *accessLogConfig) {
+		for _, name := range names {
+			config.redactHeaders[http.CanonicalHeaderKey(name)] = true
+		}
+	}
+}
+
+// RedactQuery replaces the values of the given query parameters in the
+// access logs.
+func RedactQuery(names ...string) AccessLogOption {
+	return func(config *accessLogConfig) {
+		for _, name := range names {
+			config.redactQuery[name] = true
+		}
+	}
+}
+
+// LogRequests decorates the ResponseWriter so that, once the response has
+// been written, a single record is logged for the request with its method,
+// path, route pattern, query, status, latency, bytes written, request ID and
+// the error returned by the handler, if any. Requests whose responses are
+// server errors are logged at the error level, the others at the info level.
+//
+//	wrapper := httpwrap.NewStandardWrapper().
+//		Finally(httpwrap.LogRequests(httpwrap.StandardResponseWriter(), logger))
+func LogRequests(next ResponseWriter, logger *slog.Logger, opts ...AccessLogOption) ResponseWriter {
+	config := &accessLogConfig{
+		redactHeaders: map[string]bool{
+			"Authorization":       true,
+			"Proxy-Authorization": true,
+			"Cookie":              true,
+		},
+		redactQuery: map[string]bool{},
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return func(w http.ResponseWriter, req *http.Request, res any, err error) {
+		rec, ok := w.(*responseRecorder)
+		if !ok {
+			rec = newResponseRecorder(w)
+		}
+		next(rec, req, res, err)
+		config.log(logger, req, ResponseInfo{rec: rec}, time.Since(rec.start), err)
+	}
+}
+
+func (config *accessLogConfig) log(logger *slog.Logger, req *http.Request, info ResponseInfo, latency time.Duration, err error) {
+	status := info.Status()
+	if !info.HeadersSent() {
+		status = http.StatusOK
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("path", req.URL.Path),
+		slog.String("route", req.Pattern),
+		slog.Int("status", status),
+		slog.Duration("latency", latency),
+		slog.Int64("bytes", info.BytesWritten()),
+	}
+	if req.URL.RawQuery != "" {
+		attrs = append(attrs, slog.String("query", config.query(req.URL.Query())))
+	}
+	if requestID, ok := RequestIDFromContext(req.Context()); ok {
+		attrs = append(attrs, slog.String("request_id", string(requestID)))
+	} else if requestID := req.Header.Get(RequestIDHeader); validRequestID(requestID) {
+		attrs = append(attrs, slog.String("request_id", requestID))
+	}
+	if len(config.headers) > 0 {
+		headers := []any{}
+		for _, name := range config.headers {
+			if val := req.Header.Get(name); val != "" {
+				if config.redactHeaders[http.CanonicalHeaderKey(name)] {
+					val = _redacted
+				}
+				headers = append(headers, slog.String(name, val))
+			}
+		}
+		attrs = append(attrs, slog.Group("headers", headers...))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	level := slog.LevelInfo
+	if status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	logger.LogAttrs(req.Context(), level, "request", attrs...)
+}
+
+// query returns the encoded query with the redacted parameters replaced.
+func (config *accessLogConfig) query(values url.Values) string {
+	for name, vals := range values {
+		if !config.redactQuery[name] {
+			continue
+		}
+		for i := range vals {
+			vals[i] = _redacted
+		}
+	}
+	return values.Encode()
+}
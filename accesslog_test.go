@@ -0,0 +1,76 @@
+package httpwrap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogRequests(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(buf, nil))
+	writer := LogRequests(StandardResponseWriter(WithLogger(logger)), logger,
+		LogHeaders("User-Agent", "Authorization", "X-Api-Key"),
+		RedactHeaders("x-api-key"),
+		RedactQuery("token"))
+
+	readRecord := func(t *testing.T) map[string]any {
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 1)
+		buf.Reset()
+
+		record := map[string]any{}
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+		return record
+	}
+
+	t.Run("success", func(t *testing.T) {
+		router := http.NewServeMux()
+		router.Handle("GET /pets/{name}", NewStandardWrapper().Finally(writer).Wrap(func() typedResponse {
+			return typedResponse{Value: 42}
+		}))
+
+		req := httptest.NewRequest("GET", "/pets/rex?token=secret&limit=2", nil)
+		req.Header.Set("User-Agent", "tests")
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set("X-Api-Key", "secret")
+		req.Header.Set("X-Request-ID", "req-1")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		record := readRecord(t)
+		require.Equal(t, "INFO", record["level"])
+		require.Equal(t, "request", record["msg"])
+		require.Equal(t, "GET", record["method"])
+		require.Equal(t, "/pets/rex", record["path"])
+		require.Equal(t, "GET /pets/{name}", record["route"])
+		require.Equal(t, "limit=2&token=REDACTED", record["query"])
+		require.Equal(t, float64(http.StatusOK), record["status"])
+		require.Equal(t, float64(len(`{"value":42}`)+1), record["bytes"])
+		require.Equal(t, "req-1", record["request_id"])
+		require.Equal(t, map[string]any{
+			"User-Agent":    "tests",
+			"Authorization": "REDACTED",
+			"X-Api-Key":     "REDACTED",
+		}, record["headers"])
+		require.NotContains(t, record, "error")
+	})
+
+	t.Run("error", func(t *testing.T) {
+		handler := NewStandardWrapper().Finally(writer).Wrap(func() error {
+			return fmt.Errorf("database unavailable")
+		})
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/pets", nil))
+
+		record := readRecord(t)
+		require.Equal(t, "ERROR", record["level"])
+		require.Equal(t, float64(http.StatusInternalServerError), record["status"])
+		require.Equal(t, "database unavailable", record["error"])
+	})
+}
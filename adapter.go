@@ -0,0 +1,105 @@
+package httpwrap
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// Adapter is a main function that calls its handler without reflection,
+// typically generated by the httpwrap-gen command. It can be given to Wrap,
+// Invoke and the Router in place of its handler, and runs along with the
+// befores and the Finally function of the wrapper like the handler would:
+//
+//	router.GET("/pets/{name}", GetPetByNameAdapter())
+type Adapter struct {
+	// Handler is the main function called by the adapter. It is used to
+	// validate the adapter and to describe the route, e.g: in the OpenAPI
+	// document.
+	Handler any
+
+	// Call gets the inputs of the handler and calls it, returning its
+	// outputs in order. It returns an error when the inputs cannot be
+	// read from the request, in which case the handler is not called.
+	Call func(in Inputs) ([]any, error)
+}
+
+// Inputs gives an Adapter access to the values that its handler would be
+// called with.
+type Inputs struct {
+	ctx *runctx
+}
+
+// Request returns the http request being handled, or nil when the adapter
+// is run by Invoke.
+func (in Inputs) Request() *http.Request { return in.ctx.req }
+
+// ResponseWriter returns the response writer of the request being handled,
+// or nil when the adapter is run by Invoke.
+func (in Inputs) ResponseWriter() http.ResponseWriter { return in.ctx.rw }
+
+// Get returns the value of type T that was provided by the befores of the
+// wrapper or supplied with WithOverride, if any.
+func Get[T any](in Inputs) (T, bool) {
+	val, found := in.ctx.get(reflect.TypeFor[T]())
+	if !found {
+		var zero T
+		return zero, false
+	}
+	cast, _ := val.Interface().(T)
+	return cast, true
+}
+
+// Input returns the value of type T the same way it is passed to a main
+// function: provided by the befores, or else constructed by the
+// RequestReader of the wrapper.
+func Input[T any](in Inputs) (T, error) {
+	if val, found := Get[T](in); found {
+		return val, nil
+	}
+
+	val, err := in.ctx.construct(reflect.TypeFor[T]())
+	cast, _ := val.Interface().(T)
+	return cast, err
+}
+
+// DecodeInput returns the value of type T the same way Input does, except
+// that a value read from the request by the Decoder of the wrapper is
+// decoded by the function instead, e.g: without reflection in the adapters
+// generated by httpwrap-gen. Wrappers whose RequestReader was not set with
+// WithDecoder read the value with their RequestReader, like Input.
+func DecodeInput[T any](in Inputs, decode func(d *Decoder, req *http.Request, obj *T) error) (T, error) {
+	if in.ctx.decoder == nil || in.ctx.req == nil {
+		return Input[T](in)
+	} else if val, found := Get[T](in); found {
+		return val, nil
+	}
+
+	var obj T
+	err := decode(in.ctx.decoder, in.ctx.req, &obj)
+	return obj, err
+}
+
+// handlerOf returns the handler of the main function, which is the function
+// itself unless it is an Adapter.
+func handlerOf(fn any) any {
+	if adapter, ok := fn.(Adapter); ok {
+		return adapter.Handler
+	}
+	return fn
+}
+
+func newAdapterMain(adapter Adapter) (mainFn, error) {
+	if adapter.Call == nil {
+		return mainFn{}, fmt.Errorf("adapter of %T has no Call function", adapter.Handler)
+	} else if _, isAdapter := adapter.Handler.(Adapter); isAdapter {
+		return mainFn{}, fmt.Errorf("adapter handler must not be an adapter")
+	}
+
+	main, err := newMain(adapter.Handler)
+	if err != nil {
+		return mainFn{}, err
+	}
+	main.call = adapter.Call
+	return main, nil
+}
@@ -0,0 +1,87 @@
+package httpwrap
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdapter(t *testing.T) {
+	type params struct {
+		Name string `http:"query=name"`
+	}
+	greet := func(db *overrideDB, p params) (string, error) {
+		if p.Name == "" {
+			return "", NewHTTPError(http.StatusBadRequest, "Missing name.")
+		}
+		return db.name + ":" + p.Name, nil
+	}
+
+	// The adapter does what httpwrap-gen generates for greet.
+	adapter := Adapter{
+		Handler: greet,
+		Call: func(in Inputs) ([]any, error) {
+			p0, err := Input[*overrideDB](in)
+			if err != nil {
+				return nil, err
+			}
+			p1, found := Get[params](in)
+			if req := in.Request(); !found && req != nil {
+				p1.Name = req.URL.Query().Get("name")
+			}
+			o0, o1 := greet(p0, p1)
+			return []any{o0, o1}, nil
+		},
+	}
+	wrapper := NewStandardWrapper().Before(connectDB)
+
+	serve := func(handler http.Handler, target string) (int, string) {
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, httptest.NewRequest("GET", target, nil))
+		return readResponseRecorder(t, rw)
+	}
+
+	t.Run("wrap", func(t *testing.T) {
+		statusCode, body := serve(wrapper.Wrap(adapter), "/greet?name=rex")
+		require.Equal(t, http.StatusOK, statusCode)
+		require.Equal(t, `"real:rex"`, body)
+
+		statusCode, _ = serve(wrapper.Wrap(adapter), "/greet")
+		require.Equal(t, http.StatusBadRequest, statusCode)
+
+		statusCode, body = serve(WithOverride(wrapper, &overrideDB{name: "fake"}).Wrap(adapter), "/greet?name=rex")
+		require.Equal(t, http.StatusOK, statusCode)
+		require.Equal(t, `"fake:rex"`, body)
+	})
+
+	t.Run("input error", func(t *testing.T) {
+		failing := adapter
+		failing.Call = func(Inputs) ([]any, error) { return nil, NewHTTPError(http.StatusUnauthorized, "Unauthorized.") }
+		statusCode, _ := serve(wrapper.Wrap(failing), "/greet?name=rex")
+		require.Equal(t, http.StatusUnauthorized, statusCode)
+	})
+
+	t.Run("invoke", func(t *testing.T) {
+		res, err := wrapper.Invoke(context.Background(), adapter, params{Name: "rex"})
+		require.NoError(t, err)
+		require.Equal(t, "real:rex", res)
+	})
+
+	t.Run("openapi", func(t *testing.T) {
+		router := NewRouter(wrapper)
+		router.GET("/greet", adapter)
+		doc := router.OpenAPI(OpenAPIInfo{Title: "Greeter", Version: "1.0.0"})
+		op := doc.Paths["/greet"]["get"]
+		require.Len(t, op.Parameters, 1)
+		require.Equal(t, "name", op.Parameters[0].Name)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		require.Panics(t, func() { New().Wrap(Adapter{Handler: greet}) })
+		require.Panics(t, func() { New().Wrap(Adapter{Handler: func(any) {}, Call: adapter.Call}) })
+		require.Panics(t, func() { New().Wrap(Adapter{Handler: adapter, Call: adapter.Call}) })
+	})
+}
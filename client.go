@@ -0,0 +1,215 @@
+package httpwrap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/apourchet/httpwrap/defaults"
+)
+
+// Call sends the request encoded from the parameter object with the
+// default Encoder, and decodes the response into the output object. Use
+// CallWith to encode requests with another Encoder.
+//
+//	pet, err := httpwrap.Call[GetPetParams, Pet](ctx, http.DefaultClient,
+//		"GET", "https://pets.example.com/pets/{name}", GetPetParams{Name: "rex"})
+//
+// The JSON body of successful responses is decoded into the output object,
+// whose fields with `http` tags are set from the metadata of the response,
+// the same way the StandardResponseWriter sends them: `http:"status"`,
+// `http:"header=ETag"` and `http:"cookie=session"`.
+//
+// Responses with a status code of 400 or above are returned as an error
+// implementing HTTPError, which keeps the status code and body of the
+// response. Problem Details responses are returned as a *ProblemError.
+func Call[In, Out any](ctx context.Context, client *http.Client, method, pathTemplate string, in In) (Out, error) {
+	return CallWith[In, Out](ctx, client, NewEncoder(), method, pathTemplate, in)
+}
+
+// CallWith is like Call, but encodes the request with the given Encoder.
+func CallWith[In, Out any](
+	ctx context.Context,
+	client *http.Client,
+	encoder *Encoder,
+	method, pathTemplate string,
+	in In,
+) (Out, error) {
+	var out Out
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := encoder.Encode(ctx, method, pathTemplate, in)
+	if err != nil {
+		return out, err
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json, application/problem+json;q=0.9")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return out, err
+	}
+	return DecodeResponseWith[Out](resp, encoder)
+}
+
+// DecodeResponse reads and closes the body of the response, and decodes
+// the response into the output object the same way Call does. Responses
+// with a status code of 400 or above are returned as an error implementing
+// HTTPError.
+func DecodeResponse[Out any](resp *http.Response) (Out, error) {
+	return DecodeResponseWith[Out](resp, NewEncoder())
+}
+
+// DecodeResponseWith is like DecodeResponse, but verifies and decrypts the
+// cookie fields tagged as signed or encrypted with the CookieKeys of the
+// Encoder.
+func DecodeResponseWith[Out any](resp *http.Response, encoder *Encoder) (Out, error) {
+	var out Out
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return out, err
+	} else if resp.StatusCode >= http.StatusBadRequest {
+		return out, responseErr(resp, body)
+	}
+
+	if len(strings.TrimSpace(string(body))) > 0 {
+		if err := json.Unmarshal(body, &out); err != nil {
+			return out, fmt.Errorf("failed to decode response body: %w", err)
+		}
+	}
+	if err := decodeResponseMeta(resp, &out, encoder.CookieKeys); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// responseErr returns the error carried by an error response.
+func responseErr(resp *http.Response, body []byte) error {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "application/problem+json" {
+		problem := &ProblemError{}
+		if err := json.Unmarshal(body, problem); err == nil {
+			if problem.Status == 0 {
+				problem.Status = resp.StatusCode
+			}
+			return problem
+		}
+	}
+	return httpError{
+		code: resp.StatusCode,
+		body: string(body),
+	}
+}
+
+// decodeResponseMeta sets the fields of the output object with `http` tags
+// from the status code, headers and cookies of the response.
+func decodeResponseMeta(resp *http.Response, out any, keys CookieKeys) error {
+	v, valid := defaults.DerefValue(out)
+	if !valid || v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		directive, found := field.Tag.Lookup("http")
+		if !found || directive == "" || !field.IsExported() {
+			continue
+		}
+
+		tagkey, tagval, _ := strings.Cut(directive, "=")
+		f := v.Field(i)
+		var vals []string
+		switch tagkey {
+		case "status":
+			vals = []string{fmt.Sprint(resp.StatusCode)}
+		case "header":
+			vals = resp.Header.Values(tagval)
+		case "cookie":
+			cookie, err := responseCookie(resp, tagval, keys)
+			if err != nil {
+				return err
+			} else if cookie == nil {
+				continue
+			} else if derefType(f.Type()) == _cookieType {
+				setCookieField(f, cookie)
+				continue
+			}
+			vals = []string{cookie.Value}
+		default:
+			return fmt.Errorf("unrecognized http tag %v", tagkey)
+		}
+
+		if len(vals) == 0 {
+			continue
+		} else if f.Kind() == reflect.String {
+			// Header values such as entity tags are kept as they are,
+			// where GenVal would unquote them.
+			f.SetString(vals[0])
+			continue
+		}
+		val, err := defaults.GenVal(f.Type(), vals[0], vals[1:]...)
+		if err != nil {
+			return fmt.Errorf("failed to decode response %s %q: %w", tagkey, tagval, err)
+		}
+		f.Set(val)
+	}
+	return nil
+}
+
+// responseCookie returns the cookie of the response designated by the value
+// of a cookie tag, verified or decrypted when the tag has the signed or
+// encrypted option.
+func responseCookie(resp *http.Response, tagval string, keys CookieKeys) (*http.Cookie, error) {
+	name, option, _ := strings.Cut(tagval, ",")
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name != name {
+			continue
+		}
+
+		var value string
+		var err error
+		switch option {
+		case "":
+			return cookie, nil
+		case "signed":
+			value, err = keys.Verify(name, cookie.Value)
+		case "encrypted":
+			value, err = keys.Decrypt(name, cookie.Value)
+		default:
+			return nil, fmt.Errorf("unrecognized cookie option %v", option)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode response cookie %q: %w", name, err)
+		}
+		decoded := *cookie
+		decoded.Value = value
+		return &decoded, nil
+	}
+	return nil, nil
+}
+
+func setCookieField(field reflect.Value, cookie *http.Cookie) {
+	if field.Kind() == reflect.Ptr {
+		field.Set(reflect.ValueOf(cookie))
+	} else {
+		field.Set(reflect.ValueOf(*cookie))
+	}
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
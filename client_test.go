@@ -0,0 +1,112 @@
+package httpwrap
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type clientPetParams struct {
+	Name  string `http:"segment=name"`
+	Token string `http:"bearer"`
+	Age   int    `json:"age"`
+}
+
+type clientPet struct {
+	Status  int          `http:"status"`
+	ETag    string       `http:"header=ETag"`
+	Session *http.Cookie `http:"cookie=session"`
+	Name    string       `json:"name"`
+	Age     int          `json:"age"`
+}
+
+func TestCall(t *testing.T) {
+	router := NewRouter(NewStandardWrapper())
+	router.Handle("PUT", "/pets/{name}", func(params clientPetParams) (clientPet, error) {
+		if params.Token != "secret" {
+			return clientPet{}, NewHTTPError(http.StatusForbidden, "Forbidden.")
+		}
+		return clientPet{
+			Status:  http.StatusCreated,
+			ETag:    `"v1"`,
+			Session: &http.Cookie{Name: "session", Value: "abc"},
+			Name:    params.Name,
+			Age:     params.Age,
+		}, nil
+	})
+	problems := NewRouter(NewStandardWrapper().Finally(StandardResponseWriter(WithProblemDetails())))
+	problems.Handle("GET", "/pets/{name}", func(clientPetParams) error {
+		return NewProblemError(http.StatusNotFound, "No such pet.").With("pet", "rex")
+	})
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+	problemServer := httptest.NewServer(problems)
+	defer problemServer.Close()
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		pet, err := Call[clientPetParams, clientPet](ctx, server.Client(), "PUT", server.URL+"/pets/{name}",
+			clientPetParams{Name: "rex", Token: "secret", Age: 3})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, pet.Status)
+		require.Equal(t, `"v1"`, pet.ETag)
+		require.Equal(t, "abc", pet.Session.Value)
+		require.Equal(t, "rex", pet.Name)
+		require.Equal(t, 3, pet.Age)
+	})
+
+	t.Run("http error", func(t *testing.T) {
+		_, err := Call[clientPetParams, clientPet](ctx, nil, "PUT", server.URL+"/pets/{name}",
+			clientPetParams{Name: "rex"})
+		var httpErr HTTPError
+		require.True(t, errors.As(err, &httpErr))
+		require.Equal(t, http.StatusForbidden, httpErr.StatusCode())
+		require.Equal(t, "http error: 403: Forbidden.", err.Error())
+	})
+
+	t.Run("problem details", func(t *testing.T) {
+		_, err := Call[clientPetParams, struct{}](ctx, nil, "GET", problemServer.URL+"/pets/{name}",
+			clientPetParams{Name: "rex"})
+		var problem *ProblemError
+		require.True(t, errors.As(err, &problem))
+		require.Equal(t, http.StatusNotFound, problem.Status)
+		require.Equal(t, "No such pet.", problem.Detail)
+		require.Equal(t, "rex", problem.Extensions["pet"])
+	})
+
+	t.Run("protected cookies", func(t *testing.T) {
+		keys := CookieKeys{
+			Signing:    [][]byte{[]byte("signing-key")},
+			Encryption: [][]byte{[]byte("0123456789abcdef")},
+		}
+		type session struct {
+			Signed    string       `http:"cookie=signed,signed"`
+			Encrypted *http.Cookie `http:"cookie=encrypted,encrypted"`
+		}
+		protected := NewRouter(NewStandardWrapper().Finally(StandardResponseWriter(WithCookieKeys(keys))))
+		protected.Handle("GET", "/session", func() session {
+			return session{Signed: "alice", Encrypted: &http.Cookie{Name: "encrypted", Value: "secret"}}
+		})
+		protectedServer := httptest.NewServer(protected)
+		defer protectedServer.Close()
+
+		out, err := CallWith[struct{}, session](ctx, nil, NewEncoder().WithCookieKeys(keys), "GET",
+			protectedServer.URL+"/session", struct{}{})
+		require.NoError(t, err)
+		require.Equal(t, "alice", out.Signed)
+		require.Equal(t, "secret", out.Encrypted.Value)
+
+		otherKeys := CookieKeys{
+			Signing:    [][]byte{[]byte("other-key")},
+			Encryption: [][]byte{[]byte("fedcba9876543210")},
+		}
+		_, err = CallWith[struct{}, session](ctx, nil, NewEncoder().WithCookieKeys(otherKeys), "GET",
+			protectedServer.URL+"/session", struct{}{})
+		require.True(t, errors.Is(err, ErrInvalidCookie))
+	})
+}
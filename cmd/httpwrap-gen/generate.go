@@ -0,0 +1,463 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/apourchet/httpwrap/internal/stages"
+	"golang.org/x/tools/go/packages"
+)
+
+const (
+	_httpwrapPath = "github.com/apourchet/httpwrap"
+	_defaultsPath = "github.com/apourchet/httpwrap/defaults"
+)
+
+// _tagKeys are the keys of the `http` tags read by the Decoder, and whether
+// they take a value.
+var _tagKeys = map[string]bool{
+	"header":    true,
+	"query":     true,
+	"segment":   true,
+	"cookie":    true,
+	"basicauth": true,
+	"bearer":    false,
+}
+
+// load loads the package in the directory. Type errors are ignored, since
+// the package may use adapters that are not generated yet, or that are
+// generated for an older version of the handlers.
+func load(dir string) (*types.Package, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo |
+			packages.NeedImports | packages.NeedDeps,
+		Dir: dir,
+	}
+	pkgs, err := packages.Load(cfg, ".")
+	if err != nil {
+		return nil, err
+	} else if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, found %d", dir, len(pkgs))
+	}
+
+	pkg := pkgs[0]
+	for _, pkgErr := range pkg.Errors {
+		if pkgErr.Kind != packages.TypeError {
+			return nil, pkgErr
+		}
+	}
+	return pkg.Types, nil
+}
+
+// generate returns the source of the adapters of the handlers of the
+// package.
+func generate(pkg *types.Package, handlers []string) ([]byte, error) {
+	g := newGenerator(pkg)
+	for _, handler := range handlers {
+		if err := g.adapter(strings.TrimSpace(handler)); err != nil {
+			return nil, err
+		}
+	}
+	return g.source()
+}
+
+// generator accumulates the adapters of the handlers of a package, along
+// with the decoders and imports they need.
+type generator struct {
+	pkg  *types.Package
+	body bytes.Buffer
+
+	// imports maps the paths of the imported packages to their names in
+	// the generated file, and pkgNames to their own names.
+	imports  map[string]string
+	pkgNames map[string]string
+
+	// decoders maps the struct types decoded by the adapters to the names
+	// of their decoding functions, and decoded lists them in order.
+	decoders map[*types.Named]string
+	decoded  []*types.Named
+}
+
+func newGenerator(pkg *types.Package) *generator {
+	return &generator{
+		pkg:      pkg,
+		imports:  map[string]string{},
+		pkgNames: map[string]string{},
+		decoders: map[*types.Named]string{},
+	}
+}
+
+// adapter generates the adapter of the handler, given as Func or
+// Type.Method.
+func (g *generator) adapter(handler string) error {
+	fn, recv, err := g.lookup(handler)
+	if err != nil {
+		return err
+	}
+
+	sig := fn.Signature()
+	if err := validateMain(sig); err != nil {
+		return fmt.Errorf("%s: %v", handler, err)
+	}
+
+	name, desc, callee := fn.Name()+"Adapter", fn.Name(), fn.Name()
+	params := []string{}
+	if recv != nil {
+		typeName := types.TypeString(derefType(recv), g.qualifier)
+		name = typeName + upperFirst(fn.Name()) + "Adapter"
+		desc = "(" + types.TypeString(recv, g.qualifier) + ")." + fn.Name()
+		callee = "recv." + fn.Name()
+		params = append(params, "recv "+types.TypeString(recv, g.qualifier))
+	}
+
+	httpwrap := g.importName(_httpwrapPath, "httpwrap")
+	fmt.Fprintf(&g.body, "// %s returns the Adapter of %s,\n", name, desc)
+	fmt.Fprintf(&g.body, "// which decodes the parameters of the handler with the Decoder of the wrapper.\n")
+	fmt.Fprintf(&g.body, "func %s(%s) %s.Adapter {\n", name, strings.Join(params, ", "), httpwrap)
+	fmt.Fprintf(&g.body, "return %s.Adapter{\nHandler: %s,\n", httpwrap, callee)
+	fmt.Fprintf(&g.body, "Call: func(in %s.Inputs) ([]any, error) {\n", httpwrap)
+
+	args := []string{}
+	for i := 0; i < sig.Params().Len(); i++ {
+		arg := "p" + strconv.Itoa(i)
+		g.input(arg, sig.Params().At(i).Type())
+		args = append(args, arg)
+	}
+	call := callee + "(" + strings.Join(args, ", ")
+	if sig.Variadic() {
+		call += "..."
+	}
+	call += ")"
+
+	outs := []string{}
+	for i := 0; i < sig.Results().Len(); i++ {
+		outs = append(outs, "o"+strconv.Itoa(i))
+	}
+	if len(outs) == 0 {
+		fmt.Fprintf(&g.body, "%s\nreturn nil, nil\n", call)
+	} else {
+		fmt.Fprintf(&g.body, "%s := %s\n", strings.Join(outs, ", "), call)
+		fmt.Fprintf(&g.body, "return []any{%s}, nil\n", strings.Join(outs, ", "))
+	}
+	fmt.Fprintf(&g.body, "},\n}\n}\n\n")
+	return nil
+}
+
+// lookup returns the function of the handler, and the type of its receiver
+// when it is a method.
+func (g *generator) lookup(handler string) (*types.Func, types.Type, error) {
+	typeName, methodName, isMethod := strings.Cut(handler, ".")
+	obj := g.pkg.Scope().Lookup(typeName)
+	if obj == nil {
+		return nil, nil, fmt.Errorf("%s not found in package %s", typeName, g.pkg.Path())
+	}
+
+	if !isMethod {
+		fn, ok := obj.(*types.Func)
+		if !ok {
+			return nil, nil, fmt.Errorf("%s is not a function", handler)
+		}
+		return fn, nil, nil
+	}
+
+	if _, ok := obj.(*types.TypeName); !ok {
+		return nil, nil, fmt.Errorf("%s is not a type", typeName)
+	}
+	recv := obj.Type()
+	sel, _, indirect := types.LookupFieldOrMethod(recv, true, g.pkg, methodName)
+	fn, ok := sel.(*types.Func)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s is not a method", handler)
+	}
+	if _, isPtr := fn.Signature().Recv().Type().(*types.Pointer); isPtr || indirect {
+		recv = types.NewPointer(recv)
+	}
+	return fn, recv, nil
+}
+
+// input generates the code that sets the variable to the input of the
+// handler of the type, the same way the Wrapper gets the inputs of its main
+// functions.
+func (g *generator) input(arg string, t types.Type) {
+	httpwrap := g.importName(_httpwrapPath, "httpwrap")
+	typeString := types.TypeString(t, g.qualifier)
+	named, isPtr := g.decodable(t)
+	if named == nil {
+		fmt.Fprintf(&g.body, "%s, err := %s.Input[%s](in)\n", arg, httpwrap, typeString)
+		fmt.Fprintf(&g.body, "if err != nil {\nreturn nil, err\n}\n")
+		return
+	}
+
+	decode := g.decoder(named)
+	if isPtr {
+		structType := types.TypeString(named, g.qualifier)
+		decode = fmt.Sprintf("func(d *%s.Decoder, req *%s.Request, obj **%s) error {\n*obj = new(%s)\nreturn %s(d, req, *obj)\n}",
+			httpwrap, g.importName("net/http", "http"), structType, structType, decode)
+	}
+	fmt.Fprintf(&g.body, "%s, err := %s.DecodeInput(in, %s)\n", arg, httpwrap, decode)
+	fmt.Fprintf(&g.body, "if err != nil {\nreturn nil, err\n}\n")
+}
+
+// decodable returns the struct type that the inputs of the type are decoded
+// into, and whether the inputs are pointers to it. Structs of the package
+// and structs with `http` tags are decoded, the other inputs are read by
+// the RequestReader of the wrapper.
+func (g *generator) decodable(t types.Type) (*types.Named, bool) {
+	ptr, isPtr := t.(*types.Pointer)
+	if isPtr {
+		t = ptr.Elem()
+	}
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok || named.TypeArgs().Len() > 0 {
+		return nil, false
+	}
+	st, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return nil, false
+	}
+
+	obj := named.Obj()
+	if obj.Pkg() == g.pkg {
+		return named, isPtr
+	} else if !obj.Exported() {
+		return nil, false
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		if _, found := reflect.StructTag(st.Tag(i)).Lookup("http"); found {
+			return named, isPtr
+		}
+	}
+	return nil, false
+}
+
+// decoder returns the name of the function decoding requests into the
+// struct type.
+func (g *generator) decoder(named *types.Named) string {
+	if name, found := g.decoders[named]; found {
+		return name
+	}
+
+	name := "decode" + upperFirst(named.Obj().Name())
+	if named.Obj().Pkg() != g.pkg {
+		name = "decode" + upperFirst(named.Obj().Pkg().Name()) + upperFirst(named.Obj().Name())
+	}
+	for taken := true; taken; {
+		taken = false
+		for _, other := range g.decoders {
+			if other == name {
+				name, taken = name+"_", true
+			}
+		}
+	}
+	g.decoders[named] = name
+	g.decoded = append(g.decoded, named)
+	return name
+}
+
+// decoderSource returns the source of the function decoding requests into
+// the struct type, which does what the Decoder does through reflection.
+func (g *generator) decoderSource(named *types.Named) (string, error) {
+	var buf bytes.Buffer
+	httpwrap := g.importName(_httpwrapPath, "httpwrap")
+	typeString := types.TypeString(named, g.qualifier)
+	name := g.decoders[named]
+
+	fmt.Fprintf(&buf, "// %s decodes the request into a %s, like the Decoder does.\n", name, typeString)
+	fmt.Fprintf(&buf, "func %s(d *%s.Decoder, req *%s.Request, obj *%s) error {\n",
+		name, httpwrap, g.importName("net/http", "http"), typeString)
+	fmt.Fprintf(&buf, "if err := d.DecodeBody(req, obj); err != nil {\n")
+	fmt.Fprintf(&buf, "return &%s.DecodeError{Source: \"body\", Err: err}\n}\n", httpwrap)
+
+	st := named.Underlying().(*types.Struct)
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		directive, found := reflect.StructTag(st.Tag(i)).Lookup("http")
+		if !found || directive == "" || !field.Exported() {
+			continue
+		}
+
+		tagkey, tagval, hasValue := strings.Cut(directive, "=")
+		takesValue, known := _tagKeys[tagkey]
+		if !known || takesValue != hasValue {
+			return "", fmt.Errorf("%s.%s: malformed http struct tag: %v", typeString, field.Name(), directive)
+		}
+		key, _, _ := strings.Cut(tagval, ",")
+
+		fmt.Fprintf(&buf, "if vals, err := d.Values(req, %q, %q); err != nil {\nreturn err\n", tagkey, tagval)
+		fmt.Fprintf(&buf, "} else if len(vals) > 0 {\n")
+		fmt.Fprintf(&buf, "if err := %s.Gen(&obj.%s, vals[0], vals[1:]...); err != nil {\n",
+			g.importName(_defaultsPath, "defaults"), field.Name())
+		fmt.Fprintf(&buf, "return &%s.DecodeError{Source: %q, Key: %q, Err: err}\n}\n}\n", httpwrap, tagkey, key)
+	}
+	fmt.Fprintf(&buf, "return nil\n}\n\n")
+	return buf.String(), nil
+}
+
+// source returns the formatted source of the generated file.
+func (g *generator) source() ([]byte, error) {
+	decoders := []string{}
+	for _, named := range g.decoded {
+		src, err := g.decoderSource(named)
+		if err != nil {
+			return nil, err
+		}
+		decoders = append(decoders, src)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by httpwrap-gen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", g.pkg.Name())
+
+	// Standard library imports come first, as goimports orders them.
+	paths := []string{}
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	slices.SortFunc(paths, func(a, b string) int {
+		if isStd(a) != isStd(b) {
+			if isStd(a) {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+	fmt.Fprintf(&buf, "import (\n")
+	for i, path := range paths {
+		if i > 0 && isStd(paths[i-1]) != isStd(path) {
+			fmt.Fprintf(&buf, "\n")
+		}
+		if name := g.imports[path]; name != g.pkgNames[path] {
+			fmt.Fprintf(&buf, "%s %q\n", name, path)
+		} else {
+			fmt.Fprintf(&buf, "%q\n", path)
+		}
+	}
+	fmt.Fprintf(&buf, ")\n\n")
+
+	buf.Write(g.body.Bytes())
+	for _, src := range decoders {
+		buf.WriteString(src)
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %v", err)
+	}
+	return src, nil
+}
+
+// qualifier names the packages of the types in the generated file.
+func (g *generator) qualifier(pkg *types.Package) string {
+	if pkg == g.pkg {
+		return ""
+	}
+	return g.importName(pkg.Path(), pkg.Name())
+}
+
+// importName imports the package and returns its name in the generated
+// file, which is its own name unless it clashes with another import or a
+// declaration of the package.
+func (g *generator) importName(path, name string) string {
+	if imported, found := g.imports[path]; found {
+		return imported
+	}
+
+	candidate := name
+	for i := 2; g.pkg.Scope().Lookup(candidate) != nil || slices.Contains(g.importedNames(), candidate); i++ {
+		candidate = name + strconv.Itoa(i)
+	}
+	g.imports[path], g.pkgNames[path] = candidate, name
+	return candidate
+}
+
+func (g *generator) importedNames() []string {
+	names := []string{}
+	for _, name := range g.imports {
+		names = append(names, name)
+	}
+	return names
+}
+
+// validateMain returns an error when the Wrapper would reject the handler.
+func validateMain(sig *types.Signature) error {
+	for i := 0; i < sig.Params().Len(); i++ {
+		if isInvalid(sig.Params().At(i).Type()) {
+			return fmt.Errorf("main input #%d has an invalid type", i)
+		}
+	}
+	if errs := stages.Validate(sig, stages.Main); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// isInvalid returns whether the type is, or is built from, a type that the
+// type checker could not resolve. Named types are not walked into, since
+// their declarations are checked on their own.
+func isInvalid(t types.Type) bool {
+	switch t := t.(type) {
+	case *types.Basic:
+		return t.Kind() == types.Invalid
+	case *types.Pointer:
+		return isInvalid(t.Elem())
+	case *types.Slice:
+		return isInvalid(t.Elem())
+	case *types.Array:
+		return isInvalid(t.Elem())
+	case *types.Chan:
+		return isInvalid(t.Elem())
+	case *types.Map:
+		return isInvalid(t.Key()) || isInvalid(t.Elem())
+	case *types.Signature:
+		return isInvalidTuple(t.Params()) || isInvalidTuple(t.Results())
+	case *types.Struct:
+		for i := 0; i < t.NumFields(); i++ {
+			if isInvalid(t.Field(i).Type()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isInvalidTuple returns whether any variable of the tuple has an invalid
+// type.
+func isInvalidTuple(tuple *types.Tuple) bool {
+	for i := 0; i < tuple.Len(); i++ {
+		if isInvalid(tuple.At(i).Type()) {
+			return true
+		}
+	}
+	return false
+}
+
+// isStd returns whether the import path is a package of the standard
+// library, whose first element has no dot.
+func isStd(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+func derefType(t types.Type) types.Type {
+	if ptr, ok := t.(*types.Pointer); ok {
+		return ptr.Elem()
+	}
+	return t
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	runes := []rune(s)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
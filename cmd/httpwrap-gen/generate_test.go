@@ -0,0 +1,59 @@
+package main
+
+import (
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	outPath := filepath.Join("internal", "pets", "httpwrap_gen.go")
+	pkg, err := load(filepath.Join("internal", "pets"))
+	require.NoError(t, err)
+
+	t.Run("up to date", func(t *testing.T) {
+		src, err := generate(pkg, []string{"ListPets", "Store.GetPet", "Store.AddPet", "Store.Count"})
+		require.NoError(t, err)
+
+		expected, err := os.ReadFile(outPath)
+		require.NoError(t, err)
+		require.Equal(t, string(expected), string(src), "run go generate ./...")
+	})
+
+	t.Run("invalid handlers", func(t *testing.T) {
+		for handler, msg := range map[string]string{
+			"Missing":       "not found",
+			"Color":         "is not a function",
+			"ListPets.Name": "is not a type",
+			"Store.Missing": "is not a method",
+			"Store.Pets":    "is not a method",
+		} {
+			_, err := generate(pkg, []string{handler})
+			require.Error(t, err, handler)
+			require.True(t, strings.Contains(err.Error(), msg), err.Error())
+		}
+	})
+
+	t.Run("invalid types", func(t *testing.T) {
+		invalid := types.Typ[types.Invalid]
+		for _, typ := range []types.Type{
+			invalid,
+			types.NewPointer(invalid),
+			types.NewSlice(invalid),
+			types.NewMap(types.Typ[types.String], invalid),
+		} {
+			params := types.NewTuple(types.NewVar(token.NoPos, nil, "in", typ))
+			err := validateMain(types.NewSignatureType(nil, nil, nil, params, nil, false))
+			require.Error(t, err, typ.String())
+			require.True(t, strings.Contains(err.Error(), "invalid type"), err.Error())
+		}
+
+		params := types.NewTuple(types.NewVar(token.NoPos, nil, "in", types.Typ[types.String]))
+		require.NoError(t, validateMain(types.NewSignatureType(nil, nil, nil, params, nil, false)))
+	})
+}
@@ -0,0 +1,176 @@
+// Code generated by httpwrap-gen; DO NOT EDIT.
+
+package pets
+
+import (
+	"net/http"
+
+	"github.com/apourchet/httpwrap"
+	"github.com/apourchet/httpwrap/defaults"
+)
+
+// ListPetsAdapter returns the Adapter of ListPets,
+// which decodes the parameters of the handler with the Decoder of the wrapper.
+func ListPetsAdapter() httpwrap.Adapter {
+	return httpwrap.Adapter{
+		Handler: ListPets,
+		Call: func(in httpwrap.Inputs) ([]any, error) {
+			p0, err := httpwrap.Input[*http.Request](in)
+			if err != nil {
+				return nil, err
+			}
+			p1, err := httpwrap.DecodeInput(in, func(d *httpwrap.Decoder, req *http.Request, obj **Store) error {
+				*obj = new(Store)
+				return decodeStore(d, req, *obj)
+			})
+			if err != nil {
+				return nil, err
+			}
+			p2, err := httpwrap.DecodeInput(in, decodeListParams)
+			if err != nil {
+				return nil, err
+			}
+			o0, o1 := ListPets(p0, p1, p2)
+			return []any{o0, o1}, nil
+		},
+	}
+}
+
+// StoreGetPetAdapter returns the Adapter of (*Store).GetPet,
+// which decodes the parameters of the handler with the Decoder of the wrapper.
+func StoreGetPetAdapter(recv *Store) httpwrap.Adapter {
+	return httpwrap.Adapter{
+		Handler: recv.GetPet,
+		Call: func(in httpwrap.Inputs) ([]any, error) {
+			p0, err := httpwrap.DecodeInput(in, func(d *httpwrap.Decoder, req *http.Request, obj **GetParams) error {
+				*obj = new(GetParams)
+				return decodeGetParams(d, req, *obj)
+			})
+			if err != nil {
+				return nil, err
+			}
+			p1, err := httpwrap.Input[http.ResponseWriter](in)
+			if err != nil {
+				return nil, err
+			}
+			o0, o1 := recv.GetPet(p0, p1)
+			return []any{o0, o1}, nil
+		},
+	}
+}
+
+// StoreAddPetAdapter returns the Adapter of (*Store).AddPet,
+// which decodes the parameters of the handler with the Decoder of the wrapper.
+func StoreAddPetAdapter(recv *Store) httpwrap.Adapter {
+	return httpwrap.Adapter{
+		Handler: recv.AddPet,
+		Call: func(in httpwrap.Inputs) ([]any, error) {
+			p0, err := httpwrap.DecodeInput(in, decodePet)
+			if err != nil {
+				return nil, err
+			}
+			o0 := recv.AddPet(p0)
+			return []any{o0}, nil
+		},
+	}
+}
+
+// StoreCountAdapter returns the Adapter of (Store).Count,
+// which decodes the parameters of the handler with the Decoder of the wrapper.
+func StoreCountAdapter(recv Store) httpwrap.Adapter {
+	return httpwrap.Adapter{
+		Handler: recv.Count,
+		Call: func(in httpwrap.Inputs) ([]any, error) {
+			o0 := recv.Count()
+			return []any{o0}, nil
+		},
+	}
+}
+
+// decodeStore decodes the request into a Store, like the Decoder does.
+func decodeStore(d *httpwrap.Decoder, req *http.Request, obj *Store) error {
+	if err := d.DecodeBody(req, obj); err != nil {
+		return &httpwrap.DecodeError{Source: "body", Err: err}
+	}
+	return nil
+}
+
+// decodeListParams decodes the request into a ListParams, like the Decoder does.
+func decodeListParams(d *httpwrap.Decoder, req *http.Request, obj *ListParams) error {
+	if err := d.DecodeBody(req, obj); err != nil {
+		return &httpwrap.DecodeError{Source: "body", Err: err}
+	}
+	if vals, err := d.Values(req, "query", "limit"); err != nil {
+		return err
+	} else if len(vals) > 0 {
+		if err := defaults.Gen(&obj.Limit, vals[0], vals[1:]...); err != nil {
+			return &httpwrap.DecodeError{Source: "query", Key: "limit", Err: err}
+		}
+	}
+	if vals, err := d.Values(req, "query", "tag"); err != nil {
+		return err
+	} else if len(vals) > 0 {
+		if err := defaults.Gen(&obj.Tags, vals[0], vals[1:]...); err != nil {
+			return &httpwrap.DecodeError{Source: "query", Key: "tag", Err: err}
+		}
+	}
+	if vals, err := d.Values(req, "header", "X-Color"); err != nil {
+		return err
+	} else if len(vals) > 0 {
+		if err := defaults.Gen(&obj.Color, vals[0], vals[1:]...); err != nil {
+			return &httpwrap.DecodeError{Source: "header", Key: "X-Color", Err: err}
+		}
+	}
+	if vals, err := d.Values(req, "query", "since"); err != nil {
+		return err
+	} else if len(vals) > 0 {
+		if err := defaults.Gen(&obj.Since, vals[0], vals[1:]...); err != nil {
+			return &httpwrap.DecodeError{Source: "query", Key: "since", Err: err}
+		}
+	}
+	if vals, err := d.Values(req, "bearer", ""); err != nil {
+		return err
+	} else if len(vals) > 0 {
+		if err := defaults.Gen(&obj.Token, vals[0], vals[1:]...); err != nil {
+			return &httpwrap.DecodeError{Source: "bearer", Key: "", Err: err}
+		}
+	}
+	if vals, err := d.Values(req, "cookie", "locale"); err != nil {
+		return err
+	} else if len(vals) > 0 {
+		if err := defaults.Gen(&obj.Locale, vals[0], vals[1:]...); err != nil {
+			return &httpwrap.DecodeError{Source: "cookie", Key: "locale", Err: err}
+		}
+	}
+	return nil
+}
+
+// decodeGetParams decodes the request into a GetParams, like the Decoder does.
+func decodeGetParams(d *httpwrap.Decoder, req *http.Request, obj *GetParams) error {
+	if err := d.DecodeBody(req, obj); err != nil {
+		return &httpwrap.DecodeError{Source: "body", Err: err}
+	}
+	if vals, err := d.Values(req, "segment", "name"); err != nil {
+		return err
+	} else if len(vals) > 0 {
+		if err := defaults.Gen(&obj.Name, vals[0], vals[1:]...); err != nil {
+			return &httpwrap.DecodeError{Source: "segment", Key: "name", Err: err}
+		}
+	}
+	if vals, err := d.Values(req, "query", "verbose"); err != nil {
+		return err
+	} else if len(vals) > 0 {
+		if err := defaults.Gen(&obj.Verbose, vals[0], vals[1:]...); err != nil {
+			return &httpwrap.DecodeError{Source: "query", Key: "verbose", Err: err}
+		}
+	}
+	return nil
+}
+
+// decodePet decodes the request into a Pet, like the Decoder does.
+func decodePet(d *httpwrap.Decoder, req *http.Request, obj *Pet) error {
+	if err := d.DecodeBody(req, obj); err != nil {
+		return &httpwrap.DecodeError{Source: "body", Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// Package pets holds the handlers that the tests of httpwrap-gen generate
+// adapters for.
+package pets
+
+import (
+	"net/http"
+	"slices"
+	"time"
+)
+
+//go:generate go run github.com/apourchet/httpwrap/cmd/httpwrap-gen -handlers ListPets,Store.GetPet,Store.AddPet,Store.Count
+
+type Color string
+
+type Pet struct {
+	Name  string    `json:"name"`
+	Color Color     `json:"color"`
+	Tags  []string  `json:"tags"`
+	Born  time.Time `json:"born"`
+}
+
+type Store struct {
+	Pets []Pet
+}
+
+type ListParams struct {
+	Limit  int        `http:"query=limit"`
+	Tags   []string   `http:"query=tag"`
+	Color  Color      `http:"header=X-Color"`
+	Since  *time.Time `http:"query=since"`
+	Token  string     `http:"bearer"`
+	Locale string     `http:"cookie=locale"`
+}
+
+type GetParams struct {
+	Name    string `http:"segment=name"`
+	Verbose bool   `http:"query=verbose"`
+}
+
+// ListPets returns the pets of the store matching the parameters.
+func ListPets(req *http.Request, store *Store, params ListParams) ([]Pet, error) {
+	if req != nil && req.Context().Err() != nil {
+		return nil, req.Context().Err()
+	}
+	res := []Pet{}
+	for _, pet := range store.Pets {
+		if params.Color != "" && pet.Color != params.Color {
+			continue
+		} else if params.Since != nil && pet.Born.Before(*params.Since) {
+			continue
+		} else if !containsAll(pet.Tags, params.Tags) {
+			continue
+		}
+		res = append(res, pet)
+	}
+	if params.Limit > 0 && len(res) > params.Limit {
+		res = res[:params.Limit]
+	}
+	return res, nil
+}
+
+// GetPet returns the pet with the name of the path.
+func (s *Store) GetPet(params *GetParams, rw http.ResponseWriter) (Pet, error) {
+	rw.Header().Set("X-Verbose", map[bool]string{true: "yes", false: "no"}[params.Verbose])
+	for _, pet := range s.Pets {
+		if pet.Name == params.Name {
+			return pet, nil
+		}
+	}
+	return Pet{}, errPetNotFound
+}
+
+// AddPet adds the pet of the request body to the store.
+func (s *Store) AddPet(pet Pet) error {
+	s.Pets = append(s.Pets, pet)
+	return nil
+}
+
+// Count returns the number of pets in the store.
+func (s Store) Count() int {
+	return len(s.Pets)
+}
+
+func containsAll(tags, wanted []string) bool {
+	for _, tag := range wanted {
+		if !slices.Contains(tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+var errPetNotFound = httpError{}
+
+type httpError struct{}
+
+func (httpError) Error() string   { return "pet not found" }
+func (httpError) StatusCode() int { return http.StatusNotFound }
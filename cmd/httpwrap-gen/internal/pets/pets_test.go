@@ -0,0 +1,120 @@
+package pets
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/apourchet/httpwrap"
+	"github.com/apourchet/httpwrap/defaults"
+)
+
+func TestAdapters(t *testing.T) {
+	store := &Store{Pets: []Pet{
+		{Name: "rex", Color: "brown", Tags: []string{"dog", "good"}, Born: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Name: "tom", Color: "grey", Tags: []string{"cat"}, Born: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Name: "fido", Color: "brown", Tags: []string{"dog"}, Born: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}}
+	wrapper := httpwrap.NewStandardWrapper().Before(func() *Store { return store })
+
+	// The same routes are served by the handlers and by their adapters.
+	router := httpwrap.NewRouter(wrapper)
+	router.Group("/reflect", func(g *httpwrap.Router) {
+		g.GET("/pets", ListPets)
+		g.GET("/pets/{name}", store.GetPet)
+		g.GET("/count", store.Count)
+	})
+	router.Group("/adapted", func(g *httpwrap.Router) {
+		g.GET("/pets", ListPetsAdapter())
+		g.GET("/pets/{name}", StoreGetPetAdapter(store))
+		g.GET("/count", StoreCountAdapter(*store))
+	})
+
+	serve := func(prefix, target string, header http.Header) (int, http.Header, string) {
+		req := httptest.NewRequest(http.MethodGet, prefix+target, nil)
+		for key, vals := range header {
+			req.Header[key] = vals
+		}
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, req)
+		body, err := io.ReadAll(rw.Result().Body)
+		require.NoError(t, err)
+		return rw.Code, rw.Header(), string(body)
+	}
+
+	for _, tc := range []struct {
+		target string
+		header http.Header
+	}{
+		{target: "/pets"},
+		{target: "/pets?limit=1"},
+		{target: "/pets?tag=dog&tag=good"},
+		{target: "/pets?since=2021-06-01T00:00:00Z"},
+		{target: "/pets", header: http.Header{"X-Color": {"brown"}}},
+		{target: "/pets", header: http.Header{"Authorization": {"Bearer"}}},
+		{target: "/pets?limit=many"},
+		{target: "/pets?since=yesterday"},
+		{target: "/pets/rex"},
+		{target: "/pets/rex?verbose=true"},
+		{target: "/pets/rex?verbose=maybe"},
+		{target: "/pets/felix"},
+		{target: "/count"},
+	} {
+		t.Run(tc.target, func(t *testing.T) {
+			status, header, body := serve("/reflect", tc.target, tc.header)
+			adaptedStatus, adaptedHeader, adaptedBody := serve("/adapted", tc.target, tc.header)
+			require.Equal(t, status, adaptedStatus)
+			require.Equal(t, header, adaptedHeader)
+			require.Equal(t, body, adaptedBody)
+		})
+	}
+
+	t.Run("invoke", func(t *testing.T) {
+		res, err := wrapper.Invoke(context.Background(), ListPetsAdapter(), ListParams{Color: "grey"})
+		require.NoError(t, err)
+		require.Equal(t, []Pet{store.Pets[1]}, res)
+
+		res, err = wrapper.Invoke(context.Background(), StoreGetPetAdapter(store), &GetParams{Name: "tom"},
+			httptest.NewRecorder())
+		require.NoError(t, err)
+		require.Equal(t, store.Pets[1], res)
+	})
+
+	t.Run("body", func(t *testing.T) {
+		added := &Store{}
+		h := wrapper.Wrap(StoreAddPetAdapter(added))
+		req := httptest.NewRequest(http.MethodPost, "/pets", strings.NewReader(`{"name": "rex", "color": "brown"}`))
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, []Pet{{Name: "rex", Color: "brown"}}, added.Pets)
+
+		req = httptest.NewRequest(http.MethodPost, "/pets", strings.NewReader(`{`))
+		rw = httptest.NewRecorder()
+		h.ServeHTTP(rw, req)
+		require.NotEqual(t, http.StatusOK, rw.Code)
+		require.Len(t, added.Pets, 1)
+	})
+
+	t.Run("request reader", func(t *testing.T) {
+		// The adapters read requests like the wrapper does, whether its
+		// decoder is known or hidden behind its RequestReader.
+		decoder := httpwrap.NewDecoder().WithSegmentResolver(defaults.SegmentResolverFunc(
+			func(*http.Request, string) (string, error) { return "tom", nil }))
+		for _, wrapper := range []httpwrap.Wrapper{
+			httpwrap.NewStandardWrapper().WithDecoder(decoder),
+			httpwrap.NewStandardWrapper().WithRequestReader(decoder.RequestReader()),
+		} {
+			rw := httptest.NewRecorder()
+			wrapper.Wrap(StoreGetPetAdapter(store)).ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/pets/rex", nil))
+			require.Equal(t, http.StatusOK, rw.Code)
+			require.Contains(t, rw.Body.String(), `"tom"`)
+		}
+	})
+}
@@ -0,0 +1,50 @@
+// Command httpwrap-gen generates httpwrap.Adapters for handlers, which call
+// them and decode their tagged parameters without reflection. It is meant to
+// be run by go generate in the package of the handlers:
+//
+//	//go:generate go run github.com/apourchet/httpwrap/cmd/httpwrap-gen -handlers GetPets,PetStore.GetPetByName
+//
+// For every handler, the generated file holds a function returning its
+// Adapter, e.g: GetPetsAdapter() and PetStoreGetPetByNameAdapter(store), that
+// is registered in place of the handler. The adapters decode the requests
+// with the Decoder of the wrapper when it was set with WithDecoder, and with
+// its RequestReader otherwise:
+//
+//	router.GET("/pets/{name}", PetStoreGetPetByNameAdapter(store))
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	handlers := flag.String("handlers", "", "comma-separated list of the handlers to adapt, as Func or Type.Method")
+	output := flag.String("output", "httpwrap_gen.go", "name of the generated file, in the directory of the package")
+	dir := flag.String("dir", ".", "directory of the package of the handlers")
+	flag.Parse()
+
+	if *handlers == "" {
+		fmt.Fprintln(os.Stderr, "httpwrap-gen: -handlers is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	pkg, err := load(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "httpwrap-gen: %v\n", err)
+		os.Exit(1)
+	}
+	src, err := generate(pkg, strings.Split(*handlers, ","))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "httpwrap-gen: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(filepath.Join(*dir, *output), src, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "httpwrap-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
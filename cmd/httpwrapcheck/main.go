@@ -0,0 +1,14 @@
+// Command httpwrapcheck runs the httpwrapcheck analyzer as a vet tool:
+//
+//	go vet -vettool=$(which httpwrapcheck) ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/unitchecker"
+
+	"github.com/apourchet/httpwrap/httpwrapcheck"
+)
+
+func main() {
+	unitchecker.Main(httpwrapcheck.Analyzer)
+}
@@ -0,0 +1,220 @@
+package httpwrap
+
+import (
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/apourchet/httpwrap/defaults"
+)
+
+// Codec encodes response objects into a specific media type.
+type Codec interface {
+	// ContentType returns the value of the Content-Type header of the
+	// responses encoded by the codec.
+	ContentType() string
+
+	// CanEncode returns whether the codec is able to encode the response
+	// object.
+	CanEncode(res any) bool
+
+	// Encode writes the encoded response object to the writer.
+	Encode(w io.Writer, res any) error
+}
+
+// JSONCodec returns the Codec that encodes responses as JSON. Fields with
+// an `http` tag are response metadata and are left out of the body.
+func JSONCodec() Codec { return jsonCodec{} }
+
+// XMLCodec returns the Codec that encodes struct responses as XML. Fields
+// with an `http` tag are response metadata and are left out of the body.
+func XMLCodec() Codec { return xmlCodec{} }
+
+// TextCodec returns the Codec that encodes strings, byte slices, numbers,
+// booleans and fmt.Stringer responses as plain text.
+func TextCodec() Codec { return textCodec{} }
+
+// CSVCodec returns the Codec that encodes slices of structs as CSV, with one
+// row per element preceded by a header row. Columns are named after the
+// `csv` tag of the fields, falling back to their JSON name.
+func CSVCodec() Codec { return csvCodec{} }
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string { return "application/json" }
+
+func (jsonCodec) CanEncode(any) bool { return true }
+
+func (jsonCodec) Encode(w io.Writer, res any) error {
+	meta, found, err := readResponseMeta(res)
+	if err != nil {
+		return err
+	} else if found {
+		if res, err = meta.body(res); err != nil {
+			return err
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(res)
+}
+
+type xmlCodec struct{}
+
+func (xmlCodec) ContentType() string { return "application/xml" }
+
+func (xmlCodec) CanEncode(res any) bool {
+	v, valid := defaults.DerefValue(res)
+	return valid && v.Kind() == reflect.Struct
+}
+
+func (xmlCodec) Encode(w io.Writer, res any) error {
+	if _, found, err := readResponseMeta(res); err != nil {
+		return err
+	} else if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	} else if found {
+		return encodeXMLBody(xml.NewEncoder(w), res)
+	}
+	return xml.NewEncoder(w).Encode(res)
+}
+
+type textCodec struct{}
+
+func (textCodec) ContentType() string { return "text/plain; charset=utf-8" }
+
+func (textCodec) CanEncode(res any) bool {
+	switch res.(type) {
+	case []byte, fmt.Stringer, encoding.TextMarshaler:
+		return true
+	}
+
+	v, valid := defaults.DerefValue(res)
+	if !valid {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func (textCodec) Encode(w io.Writer, res any) error {
+	switch cast := res.(type) {
+	case []byte:
+		_, err := w.Write(cast)
+		return err
+	case fmt.Stringer:
+		_, err := io.WriteString(w, cast.String())
+		return err
+	case encoding.TextMarshaler:
+		text, err := cast.MarshalText()
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(text)
+		return err
+	}
+
+	v, _ := defaults.DerefValue(res)
+	_, err := fmt.Fprint(w, v.Interface())
+	return err
+}
+
+type csvCodec struct{}
+
+func (csvCodec) ContentType() string { return "text/csv; charset=utf-8" }
+
+func (csvCodec) CanEncode(res any) bool {
+	v, valid := defaults.DerefValue(res)
+	if !valid || (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) {
+		return false
+	}
+	elem := v.Type().Elem()
+	for elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	return elem.Kind() == reflect.Struct
+}
+
+func (csvCodec) Encode(w io.Writer, res any) error {
+	v, _ := defaults.DerefValue(res)
+	elem := v.Type().Elem()
+	for elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+
+	columns, header := []int{}, []string{}
+	for i := 0; i < elem.NumField(); i++ {
+		field := elem.Field(i)
+		name := csvFieldName(field)
+		if !field.IsExported() || name == "-" || field.Tag.Get("http") != "" {
+			continue
+		}
+		columns = append(columns, i)
+		header = append(header, name)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for i := 0; i < v.Len(); i++ {
+		row := make([]string, len(columns))
+		if item, valid := defaults.DerefValue(v.Index(i).Interface()); valid {
+			for j, column := range columns {
+				row[j] = csvValue(item.Field(column))
+			}
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvFieldName returns the name of the CSV column of the struct field.
+func csvFieldName(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("csv"), ","); name != "" {
+		return name
+	}
+	return jsonFieldName(field)
+}
+
+func csvValue(field reflect.Value) string {
+	for field.Kind() == reflect.Ptr || field.Kind() == reflect.Interface {
+		if field.IsNil() {
+			return ""
+		}
+		field = field.Elem()
+	}
+
+	switch cast := field.Interface().(type) {
+	case time.Time:
+		return cast.Format(time.RFC3339)
+	case []byte:
+		return string(cast)
+	}
+
+	switch field.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		encoded, err := json.Marshal(field.Interface())
+		if err != nil {
+			return ""
+		}
+		return string(encoded)
+	}
+	return fmt.Sprint(field.Interface())
+}
@@ -0,0 +1,92 @@
+package httpwrap
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// xmlPetBase is embedded in XML responses. Its methods keep it from being
+// embedded with reflect.StructOf.
+type xmlPetBase struct {
+	Name string `xml:"name"`
+	ID   int    `xml:"id"`
+}
+
+func (xmlPetBase) Kind() string { return "pet" }
+
+type xmlPetOwner struct {
+	Owner string `xml:"owner"`
+}
+
+type embeddedPet struct {
+	Status int `http:"status"`
+	xmlPetBase
+	*xmlPetOwner
+	time.Duration
+	ID int `xml:"id"`
+}
+
+func TestCodecs(t *testing.T) {
+	t.Run("xml", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.True(t, XMLCodec().CanEncode(&xmlPet{}))
+		require.False(t, XMLCodec().CanEncode([]xmlPet{}))
+		require.NoError(t, XMLCodec().Encode(buf, xmlPet{Name: "rex"}))
+		require.Equal(t, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<xmlPet><name>rex</name></xmlPet>", buf.String())
+	})
+
+	t.Run("xml metadata", func(t *testing.T) {
+		type createdPet struct {
+			Status   int    `http:"status"`
+			Location string `http:"header=Location"`
+			Name     string `xml:"name"`
+		}
+		type namedPet struct {
+			XMLName struct{} `xml:"pet"`
+			Session string   `http:"cookie=session"`
+			Name    string   `xml:"name,attr"`
+		}
+
+		buf := &bytes.Buffer{}
+		require.NoError(t, XMLCodec().Encode(buf, &createdPet{Status: 201, Location: "/pets/rex", Name: "rex"}))
+		require.Equal(t, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<createdPet><name>rex</name></createdPet>", buf.String())
+
+		buf.Reset()
+		require.NoError(t, XMLCodec().Encode(buf, namedPet{Session: "abc", Name: "rex"}))
+		require.Equal(t, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<pet name=\"rex\"></pet>", buf.String())
+
+		buf.Reset()
+		require.NoError(t, XMLCodec().Encode(buf, embeddedPet{Status: 201, xmlPetBase: xmlPetBase{Name: "rex", ID: 1}, ID: 2, Duration: 3}))
+		require.Equal(t, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<embeddedPet><name>rex</name><Duration>3</Duration><id>2</id></embeddedPet>", buf.String())
+	})
+
+	t.Run("text", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.True(t, TextCodec().CanEncode(42))
+		require.False(t, TextCodec().CanEncode(xmlPet{}))
+		require.NoError(t, TextCodec().Encode(buf, 42))
+		require.Equal(t, "42", buf.String())
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.True(t, CSVCodec().CanEncode([]*csvPet{}))
+		require.False(t, CSVCodec().CanEncode(csvPet{}))
+		require.False(t, CSVCodec().CanEncode([]int{}))
+		require.NoError(t, CSVCodec().Encode(buf, []*csvPet{{Name: "rex"}, nil}))
+		require.Equal(t, "name,cat\nrex,0\n,\n", buf.String())
+	})
+
+	t.Run("json omits metadata", func(t *testing.T) {
+		type response struct {
+			ETag  string `http:"header=ETag"`
+			Value int    `json:"value"`
+		}
+		buf := &bytes.Buffer{}
+		require.NoError(t, JSONCodec().Encode(buf, response{ETag: "x", Value: 1}))
+		require.Equal(t, "{\"value\":1}\n", buf.String())
+	})
+}
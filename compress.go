@@ -0,0 +1,287 @@
+package httpwrap
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DefaultCompressionMinSize is the minimum size in bytes of the responses
+// compressed by CompressResponses.
+const DefaultCompressionMinSize = 1024
+
+// _incompressibleTypes are the media types, or media type prefixes, of
+// content that is already compressed.
+var _incompressibleTypes = []string{
+	"image/",
+	"video/",
+	"audio/",
+	"font/woff",
+	"application/gzip",
+	"application/x-gzip",
+	"application/zip",
+	"application/zstd",
+	"application/x-bzip2",
+	"application/x-xz",
+	"application/x-7z-compressed",
+	"application/x-rar-compressed",
+	"application/pdf",
+}
+
+// CompressOption configures the compression of the responses by Compress.
+type CompressOption func(*compressConfig)
+
+type compressConfig struct {
+	// logger is the logger of the errors that happen while compressing the
+	// responses.
+	logger *slog.Logger
+}
+
+// CompressLogger sets the logger of the errors that happen while
+// compressing the responses. It defaults to slog.Default().
+func CompressLogger(logger *slog.Logger) CompressOption {
+	return func(config *compressConfig) {
+		config.logger = logger
+	}
+}
+
+// CompressResponses decorates the ResponseWriter so that the responses it
+// sends are compressed with gzip or deflate, as negotiated with the
+// Accept-Encoding header of the request. Responses smaller than
+// DefaultCompressionMinSize are sent as they are.
+func CompressResponses(next ResponseWriter, opts ...CompressOption) ResponseWriter {
+	return Compress(next, DefaultCompressionMinSize, opts...)
+}
+
+// Compress decorates the ResponseWriter so that the responses it sends are
+// compressed with gzip or deflate, as negotiated with the Accept-Encoding
+// header of the request. Responses are only compressed once they reach
+// minSize bytes, or when they are flushed. Responses to HEAD requests,
+// partial responses, responses that already have a Content-Encoding and
+// responses whose Content-Type is already compressed (e.g: images, archives)
+// are never compressed.
+//
+// The strong ETag of a compressed response is sent as a weak ETag, since the
+// compressed content is not the one it was computed from. Not Modified
+// responses to requests that validated that weak ETag send it back as well.
+func Compress(next ResponseWriter, minSize int, opts ...CompressOption) ResponseWriter {
+	config := &compressConfig{logger: slog.Default()}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return func(w http.ResponseWriter, req *http.Request, res any, err error) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(req.Header.Get("Accept-Encoding"))
+		if encoding == "" || req.Method == http.MethodHead {
+			next(w, req, res, err)
+			return
+		}
+
+		cw := &compressWriter{
+			ResponseWriter: w,
+			req:            req,
+			logger:         config.logger,
+			encoding:       encoding,
+			minSize:        minSize,
+		}
+		defer cw.Close()
+		next(cw, req, res, err)
+	}
+}
+
+// negotiateEncoding returns the content coding of the Accept-Encoding
+// header with the highest quality value among gzip and deflate, preferring
+// gzip. It returns an empty string if neither is acceptable.
+func negotiateEncoding(accept string) string {
+	qualities := map[string]float64{}
+	for _, part := range strings.Split(accept, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		qualities[strings.ToLower(strings.TrimSpace(coding))] = quality
+	}
+
+	best, bestQuality := "", 0.0
+	for _, coding := range []string{"gzip", "deflate"} {
+		quality, found := qualities[coding]
+		if !found {
+			quality, found = qualities["*"]
+		}
+		if found && quality > bestQuality {
+			best, bestQuality = coding, quality
+		}
+	}
+	return best
+}
+
+// compressWriter buffers the beginning of the response until it knows
+// whether to compress it, which is once the response reaches the minimum
+// size, is flushed or is closed.
+type compressWriter struct {
+	http.ResponseWriter
+	req      *http.Request
+	logger   *slog.Logger
+	encoding string
+	minSize  int
+
+	status     int
+	buf        []byte
+	decided    bool
+	compressor io.WriteCloser
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.decided || cw.status != 0 {
+		return
+	} else if code >= 100 && code < 200 {
+		cw.ResponseWriter.WriteHeader(code)
+		return
+	}
+	cw.status = code
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+	if cw.decided {
+		return cw.writer().Write(p)
+	}
+
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) >= cw.minSize {
+		if err := cw.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush compresses the response if it can be compressed, regardless of its
+// size, and flushes everything written so far to the client.
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		if cw.status == 0 {
+			cw.status = http.StatusOK
+		}
+		if err := cw.decide(true); err != nil {
+			cw.logger.ErrorContext(cw.req.Context(), "error flushing response", "error", err)
+			return
+		}
+	}
+	if flusher, ok := cw.compressor.(interface{ Flush() error }); ok {
+		if err := flusher.Flush(); err != nil {
+			cw.logger.ErrorContext(cw.req.Context(), "error flushing response", "error", err)
+		}
+	}
+	if err := http.NewResponseController(cw.ResponseWriter).Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		cw.logger.ErrorContext(cw.req.Context(), "error flushing response", "error", err)
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (cw *compressWriter) Unwrap() http.ResponseWriter { return cw.ResponseWriter }
+
+// Close sends what remains of the response.
+func (cw *compressWriter) Close() {
+	if !cw.decided && cw.status != 0 {
+		if err := cw.decide(false); err != nil {
+			cw.logger.ErrorContext(cw.req.Context(), "error writing response", "error", err)
+		}
+	}
+	if cw.compressor != nil {
+		if err := cw.compressor.Close(); err != nil {
+			cw.logger.ErrorContext(cw.req.Context(), "error writing response", "error", err)
+		}
+	}
+}
+
+// decide sends the header of the response, compressed if requested and
+// possible, followed by the buffered content.
+func (cw *compressWriter) decide(compress bool) error {
+	cw.decided = true
+	header := cw.Header()
+	if compress && cw.compressible() {
+		header.Set("Content-Encoding", cw.encoding)
+		header.Del("Content-Length")
+		weakenETag(header)
+		if cw.encoding == "gzip" {
+			cw.compressor = gzip.NewWriter(cw.ResponseWriter)
+		} else {
+			cw.compressor = zlib.NewWriter(cw.ResponseWriter)
+		}
+	} else if cw.status == http.StatusNotModified && cw.validatedCompressed() {
+		weakenETag(header)
+	}
+
+	cw.ResponseWriter.WriteHeader(cw.status)
+	buf := cw.buf
+	cw.buf = nil
+	_, err := cw.writer().Write(buf)
+	return err
+}
+
+func (cw *compressWriter) compressible() bool {
+	header := cw.Header()
+	switch {
+	case cw.status < 200, cw.status == http.StatusNoContent, cw.status == http.StatusNotModified,
+		cw.status == http.StatusPartialContent:
+		return false
+	case header.Get("Content-Encoding") != "", header.Get("Content-Range") != "":
+		return false
+	}
+
+	// Once compressed, the content can no longer be sniffed by net/http.
+	if header.Get("Content-Type") == "" {
+		header.Set("Content-Type", http.DetectContentType(cw.buf))
+	}
+	contentType := baseMediaType(header.Get("Content-Type"))
+	for _, incompressible := range _incompressibleTypes {
+		if strings.HasPrefix(contentType, incompressible) {
+			return false
+		}
+	}
+	return true
+}
+
+// validatedCompressed returns whether the If-None-Match header of the
+// request holds the weak ETag of the compressed response, rather than the
+// strong ETag of the uncompressed one.
+func (cw *compressWriter) validatedCompressed() bool {
+	etag := cw.Header().Get("ETag")
+	if etag == "" || strings.HasPrefix(etag, "W/") {
+		return false
+	}
+	for _, candidate := range parseETags(cw.req.Header.Get("If-None-Match")) {
+		if candidate == "W/"+etag {
+			return true
+		}
+	}
+	return false
+}
+
+// weakenETag marks the ETag of the header as weak, if it is a strong one.
+func weakenETag(header http.Header) {
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		header.Set("ETag", "W/"+etag)
+	}
+}
+
+func (cw *compressWriter) writer() io.Writer {
+	if cw.compressor != nil {
+		return cw.compressor
+	}
+	return cw.ResponseWriter
+}
@@ -0,0 +1,139 @@
+package httpwrap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompress(t *testing.T) {
+	large := typedContext{String: strings.Repeat("a", 2048)}
+	wrapper := New().Finally(CompressResponses(StandardResponseWriter()))
+
+	t.Run("negotiate encoding", func(t *testing.T) {
+		require.Equal(t, "gzip", negotiateEncoding("gzip, deflate"))
+		require.Equal(t, "deflate", negotiateEncoding("gzip;q=0.5, deflate"))
+		require.Equal(t, "gzip", negotiateEncoding("*"))
+		require.Equal(t, "deflate", negotiateEncoding("gzip;q=0, *;q=0.1"))
+		require.Equal(t, "", negotiateEncoding("br, identity"))
+		require.Equal(t, "", negotiateEncoding(""))
+	})
+
+	t.Run("gzip", func(t *testing.T) {
+		handler := wrapper.Wrap(func() typedContext { return large })
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/endpoint", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		handler.ServeHTTP(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+		require.Equal(t, "gzip", result.Header.Get("Content-Encoding"))
+		require.Equal(t, "Accept-Encoding", result.Header.Get("Vary"))
+		require.Equal(t, "application/json", result.Header.Get("Content-Type"))
+
+		reader, err := gzip.NewReader(result.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.Contains(t, string(body), large.String)
+	})
+
+	t.Run("deflate", func(t *testing.T) {
+		handler := wrapper.Wrap(func() typedContext { return large })
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/endpoint", nil)
+		req.Header.Set("Accept-Encoding", "deflate")
+		handler.ServeHTTP(rw, req)
+
+		require.Equal(t, "deflate", rw.Result().Header.Get("Content-Encoding"))
+		reader, err := zlib.NewReader(rw.Result().Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.Contains(t, string(body), large.String)
+	})
+
+	t.Run("below minimum size", func(t *testing.T) {
+		handler := wrapper.Wrap(func() typedResponse { return typedResponse{Value: 42} })
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/endpoint", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		handler.ServeHTTP(rw, req)
+
+		statusCode, body := readResponseRecorder(t, rw)
+		require.Equal(t, http.StatusOK, statusCode)
+		require.Equal(t, `{"value":42}`, body)
+		require.Empty(t, rw.Result().Header.Get("Content-Encoding"))
+	})
+
+	t.Run("already compressed", func(t *testing.T) {
+		handler := wrapper.Wrap(func() FileResponse {
+			return FileResponse{
+				Content:     bytes.NewReader(bytes.Repeat([]byte{0}, 4096)),
+				ContentType: "image/png",
+			}
+		})
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/image", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		handler.ServeHTTP(rw, req)
+		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+		require.Empty(t, rw.Result().Header.Get("Content-Encoding"))
+		require.Equal(t, 4096, rw.Body.Len())
+	})
+
+	t.Run("error responses", func(t *testing.T) {
+		handler := wrapper.Wrap(func() error {
+			return NewHTTPError(http.StatusNotFound, "%s", strings.Repeat("missing ", 200))
+		})
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/endpoint", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		handler.ServeHTTP(rw, req)
+		require.Equal(t, http.StatusNotFound, rw.Result().StatusCode)
+		require.Equal(t, "gzip", rw.Result().Header.Get("Content-Encoding"))
+	})
+	t.Run("etags", func(t *testing.T) {
+		handler := New().Finally(CompressResponses(StandardResponseWriter(WithETags()))).
+			Wrap(func() typedContext { return large })
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/endpoint", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		handler.ServeHTTP(rw, req)
+		require.Equal(t, "gzip", rw.Result().Header.Get("Content-Encoding"))
+		etag := rw.Result().Header.Get("ETag")
+		require.True(t, strings.HasPrefix(etag, `W/"`))
+
+		rw = httptest.NewRecorder()
+		req = httptest.NewRequest("GET", "/endpoint", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		req.Header.Set("If-None-Match", etag)
+		handler.ServeHTTP(rw, req)
+		require.Equal(t, http.StatusNotModified, rw.Result().StatusCode)
+		require.Equal(t, etag, rw.Result().Header.Get("ETag"))
+		require.Equal(t, "Accept-Encoding", rw.Result().Header.Get("Vary"))
+		require.Empty(t, rw.Result().Header.Get("Content-Encoding"))
+
+		rw = httptest.NewRecorder()
+		req = httptest.NewRequest("GET", "/endpoint", nil)
+		req.Header.Set("If-None-Match", strings.TrimPrefix(etag, "W/"))
+		handler.ServeHTTP(rw, req)
+		require.Equal(t, http.StatusNotModified, rw.Result().StatusCode)
+		require.Equal(t, strings.TrimPrefix(etag, "W/"), rw.Result().Header.Get("ETag"))
+		require.Equal(t, "Accept-Encoding", rw.Result().Header.Get("Vary"))
+	})
+}
@@ -1,6 +1,7 @@
 package httpwrap
 
 import (
+	"context"
 	"net/http"
 	"reflect"
 )
@@ -10,9 +11,16 @@ type runctx struct {
 	req  *http.Request
 	cons RequestReader
 
+	// decoder is the decoder of cons, if it is known.
+	decoder *Decoder
+
 	response    reflect.Value
 	results     map[reflect.Type]param
 	resultSlice []param
+
+	// overrides holds the values supplied in place of the values of their
+	// types.
+	overrides overrides
 }
 
 type param struct {
@@ -21,24 +29,85 @@ type param struct {
 	i any
 }
 
+// overrides are the values supplied with WithOverride, in the order they
+// were supplied.
+type overrides []param
+
+// find returns the override of the type.
+func (o overrides) find(t reflect.Type) (reflect.Value, bool) {
+	for _, p := range o {
+		if p.t == t {
+			return p.v, true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// lookup returns the override of the type or, for interfaces, the last
+// override whose type implements it.
+func (o overrides) lookup(t reflect.Type) (reflect.Value, bool) {
+	if v, found := o.find(t); found || t.Kind() != reflect.Interface {
+		return v, found
+	}
+	for i := len(o) - 1; i >= 0; i-- {
+		if o[i].t.Implements(t) {
+			return o[i].v, true
+		}
+	}
+	return reflect.Value{}, false
+}
+
 func newRunCtx(
 	rw http.ResponseWriter,
 	req *http.Request,
 	cons RequestReader,
 ) *runctx {
+	rec := newResponseRecorder(rw)
 	ctx := &runctx{
 		req:         req,
-		rw:          rw,
+		rw:          rec,
 		cons:        cons,
 		response:    reflect.Zero(reflect.TypeOf((*any)(nil)).Elem()),
 		results:     map[reflect.Type]param{},
 		resultSlice: []param{},
 	}
 	ctx.provide(req)
-	ctx.provide(rw)
+	ctx.provide(rec)
+	ctx.provide(ResponseInfo{rec: rec})
 	return ctx
 }
 
+// newInvokeCtx returns the context of a run that is not tied to an http
+// request. The inputs are provided, and the other parameters are left zero.
+func newInvokeCtx(c context.Context, inputs []any) *runctx {
+	ctx := &runctx{
+		cons:        emptyRequestReader,
+		response:    reflect.Zero(reflect.TypeOf((*any)(nil)).Elem()),
+		results:     map[reflect.Type]param{},
+		resultSlice: []param{},
+	}
+	ctx.provide(c)
+	for _, input := range inputs {
+		ctx.provide(input)
+	}
+	return ctx
+}
+
+// detach gives the context of a run that is not tied to an http request a
+// request carrying the context and a response writer that discards the
+// response, for the Finally function to write to.
+func (ctx *runctx) detach(c context.Context) {
+	req, err := http.NewRequestWithContext(c, http.MethodGet, "/", http.NoBody)
+	if err != nil {
+		return
+	}
+	rec := newResponseRecorder(&discardWriter{header: http.Header{}})
+	ctx.rw = rec
+	ctx.provide(req)
+	ctx.provide(rec)
+	ctx.provide(ResponseInfo{rec: rec})
+}
+
 func (ctx *runctx) provide(i any) {
 	if i == nil {
 		return
@@ -54,8 +123,27 @@ func (ctx *runctx) provide(i any) {
 			return
 		}
 	}
+	if _, overridden := ctx.overrides.find(p.t); overridden {
+		return
+	}
 	ctx.results[p.t] = p
 	ctx.resultSlice = append(ctx.resultSlice, p)
+
+	// Befores can replace the request, e.g: to attach values to its context.
+	if req, ok := i.(*http.Request); ok {
+		ctx.req = req
+	}
+}
+
+// lastError returns the last non-nil error provided after the first n
+// results.
+func (ctx *runctx) lastError(n int) error {
+	for i := len(ctx.resultSlice) - 1; i >= n; i-- {
+		if err, ok := ctx.resultSlice[i].i.(error); ok {
+			return err
+		}
+	}
+	return nil
 }
 
 func (ctx *runctx) get(t reflect.Type) (val reflect.Value, found bool) {
@@ -66,6 +154,10 @@ func (ctx *runctx) get(t reflect.Type) (val reflect.Value, found bool) {
 		return ctx.response, false
 	}
 
+	if override, found := ctx.overrides.lookup(t); found {
+		return override, true
+	}
+
 	if t.Kind() != reflect.Interface {
 		param, found := ctx.results[t]
 		return param.v, found
@@ -0,0 +1,157 @@
+package httpwrap
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// _conventionMethods maps the prefixes of the controller methods to the
+// HTTP methods of their routes.
+var _conventionMethods = map[string]string{
+	"Get":     http.MethodGet,
+	"Head":    http.MethodHead,
+	"Post":    http.MethodPost,
+	"Put":     http.MethodPut,
+	"Patch":   http.MethodPatch,
+	"Delete":  http.MethodDelete,
+	"Options": http.MethodOptions,
+	"List":    http.MethodGet,
+}
+
+// RegisterController registers the exported methods of the controller as
+// routes of the router. When the controller has a `Routes() []Route`
+// method, exactly those routes are registered. Otherwise routes are derived
+// from the names of the methods that start with an HTTP method, or with List
+// for GET:
+//
+//	GetPets                   → GET /pets
+//	ListPet                   → GET /pets
+//	PostPets                  → POST /pets
+//	GetPetByName              → GET /pets/{name}
+//	DeletePetPhotoByIDAndName → DELETE /pet-photos/{id}/{name}
+//	GetHealth                 → GET /health
+//
+// The resources of List methods and of routes with segments are pluralized,
+// since they belong to a collection. The others are used as named, so that
+// singletons keep their name.
+//
+// Other methods are left alone. When the controller has a `Befores() []any`
+// method, its befores run before every method of the controller, after the
+// befores of the router. It panics if the controller has no routes.
+func RegisterController(router *Router, ctrl any) {
+	routes := controllerRoutes(ctrl)
+	if len(routes) == 0 {
+		panic(fmt.Errorf("controller %T has no routes", ctrl))
+	}
+
+	router.Group("", func(g *Router) {
+		if cast, ok := ctrl.(interface{ Befores() []any }); ok {
+			g.Use(cast.Befores()...)
+		}
+		for _, route := range routes {
+			g.Handle(route.Method, route.Path, route.Handler)
+		}
+	})
+}
+
+// controllerRoutes returns the routes of the controller, either listed by
+// the controller itself or derived from the names of its methods.
+func controllerRoutes(ctrl any) []Route {
+	if cast, ok := ctrl.(interface{ Routes() []Route }); ok {
+		return cast.Routes()
+	}
+
+	routes := []Route{}
+	v := reflect.ValueOf(ctrl)
+	for i := 0; i < v.NumMethod(); i++ {
+		method, path, ok := conventionRoute(v.Type().Method(i).Name)
+		if ok {
+			routes = append(routes, Route{Method: method, Path: path, Handler: v.Method(i).Interface()})
+		}
+	}
+	return routes
+}
+
+// conventionRoute returns the HTTP method and path of the route of a
+// controller method, derived from its name.
+func conventionRoute(name string) (string, string, bool) {
+	words := camelWords(name)
+	if len(words) == 0 {
+		return "", "", false
+	}
+	method, ok := _conventionMethods[words[0]]
+	if !ok {
+		return "", "", false
+	}
+
+	resource, segments := words[1:], []string{}
+	for i, word := range resource {
+		if word == "By" {
+			resource, segments = words[1:i+1], words[i+2:]
+			break
+		}
+	}
+
+	path := ""
+	if len(resource) > 0 {
+		if words[0] == "List" || len(segments) > 0 {
+			resource[len(resource)-1] = pluralize(resource[len(resource)-1])
+		}
+		path = "/" + strings.ToLower(strings.Join(resource, "-"))
+	}
+
+	segment := []string{}
+	for _, word := range append(segments, "And") {
+		if word != "And" {
+			segment = append(segment, word)
+			continue
+		} else if len(segment) > 0 {
+			path += "/{" + strings.ToLower(segment[0]) + strings.Join(segment[1:], "") + "}"
+		}
+		segment = segment[:0]
+	}
+
+	if path == "" {
+		path = "/"
+	}
+	return method, path, true
+}
+
+// camelWords splits a CamelCase identifier into its words. Acronyms are kept
+// together, e.g: GetPetByOwnerID becomes Get, Pet, By, Owner and ID.
+func camelWords(name string) []string {
+	runes := []rune(name)
+	words, start := []string{}, 0
+	for i := 1; i < len(runes); i++ {
+		lowerToUpper := !unicode.IsUpper(runes[i-1]) && unicode.IsUpper(runes[i])
+		acronymEnd := unicode.IsUpper(runes[i-1]) && unicode.IsUpper(runes[i]) &&
+			i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if lowerToUpper || acronymEnd {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if len(runes) > 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// pluralize returns the plural of an English noun, keeping the words that
+// already end with an s.
+func pluralize(word string) string {
+	lower := strings.ToLower(word)
+	switch {
+	case strings.HasSuffix(lower, "s"):
+		return word
+	case strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return word + "es"
+	case len(lower) > 1 && strings.HasSuffix(lower, "y") && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return word[:len(word)-1] + "ies"
+	}
+	return word + "s"
+}
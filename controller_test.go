@@ -0,0 +1,110 @@
+package httpwrap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type petController struct {
+	calls []string
+}
+
+type ownedPetParams struct {
+	OwnerID string `http:"segment=ownerID"`
+	Name    string `http:"segment=name"`
+}
+
+func (ctrl *petController) Befores() []any {
+	return []any{func() { ctrl.calls = append(ctrl.calls, "before") }}
+}
+
+func (ctrl *petController) GetPets() []string { return []string{"rex"} }
+
+func (ctrl *petController) GetPetByName(params petParams) string { return params.Name }
+
+func (ctrl *petController) PostPets() string { return "created" }
+
+func (ctrl *petController) GetHealth() string { return "ok" }
+
+func (ctrl *petController) DeletePetByOwnerIDAndName(params ownedPetParams) string {
+	return params.OwnerID + "/" + params.Name
+}
+
+func (ctrl *petController) Helper() string { return "not a route" }
+
+type explicitController struct{}
+
+func (explicitController) Routes() []Route {
+	return []Route{{Method: "GET", Path: "/ping", Handler: func() string { return "pong" }}}
+}
+
+func TestRegisterController(t *testing.T) {
+	ctrl := &petController{}
+	router := NewRouter(NewStandardWrapper())
+	router.Group("/api", func(g *Router) {
+		RegisterController(g, ctrl)
+		RegisterController(g, explicitController{})
+	})
+
+	paths := []string{}
+	for _, route := range router.Routes() {
+		paths = append(paths, route.Method+" "+route.Path)
+	}
+	require.ElementsMatch(t, []string{
+		"GET /api/pets",
+		"GET /api/pets/{name}",
+		"POST /api/pets",
+		"GET /api/health",
+		"DELETE /api/pets/{ownerID}/{name}",
+		"GET /api/ping",
+	}, paths)
+
+	rw := httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest("DELETE", "/api/pets/42/rex", nil))
+	statusCode, body := readResponseRecorder(t, rw)
+	require.Equal(t, http.StatusOK, statusCode)
+	require.Equal(t, `"42/rex"`, body)
+	require.Equal(t, []string{"before"}, ctrl.calls)
+
+	rw = httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest("GET", "/api/health", nil))
+	_, body = readResponseRecorder(t, rw)
+	require.Equal(t, `"ok"`, body)
+
+	rw = httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest("GET", "/api/ping", nil))
+	_, body = readResponseRecorder(t, rw)
+	require.Equal(t, `"pong"`, body)
+
+	require.Panics(t, func() { RegisterController(router, struct{}{}) })
+}
+
+func TestConventionRoute(t *testing.T) {
+	for name, expected := range map[string]string{
+		"GetPets":                   "GET /pets",
+		"PostPets":                  "POST /pets",
+		"PostPet":                   "POST /pet",
+		"ListPet":                   "GET /pets",
+		"ListBoxes":                 "GET /boxes",
+		"GetHealth":                 "GET /health",
+		"GetStatus":                 "GET /status",
+		"PutUserSettings":           "PUT /user-settings",
+		"GetPetByName":              "GET /pets/{name}",
+		"PutCategoryByID":           "PUT /categories/{id}",
+		"PatchBoxByOwnerID":         "PATCH /boxes/{ownerID}",
+		"DeletePetPhotoByIDAndName": "DELETE /pet-photos/{id}/{name}",
+		"Get":                       "GET /",
+	} {
+		method, path, ok := conventionRoute(name)
+		require.True(t, ok, name)
+		require.Equal(t, expected, method+" "+path, name)
+	}
+
+	_, _, ok := conventionRoute("Getaway")
+	require.False(t, ok)
+	_, _, ok = conventionRoute("UpdatePet")
+	require.False(t, ok)
+}
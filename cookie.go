@@ -0,0 +1,193 @@
+package httpwrap
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrInvalidCookie is the error returned when a signed or encrypted cookie
+// cannot be verified with any of the configured keys.
+var ErrInvalidCookie = errors.New("invalid cookie")
+
+var _cookieEncoding = base64.RawURLEncoding
+
+// CookieKeys holds the keys used to sign and encrypt cookies. New cookies
+// are always produced with the first key of each list, while every key is
+// tried when reading cookies back. Keys can therefore be rotated by adding
+// the new key in front and dropping the oldest one once the cookies it
+// produced have expired.
+type CookieKeys struct {
+	// Signing holds the HMAC-SHA256 keys used for signed cookies.
+	Signing [][]byte
+
+	// Encryption holds the AES-GCM keys used for encrypted cookies. Each
+	// key must be 16, 24 or 32 bytes long.
+	Encryption [][]byte
+}
+
+// Sign returns the signed form of the cookie value. The name of the cookie
+// is part of the signature, so that a value cannot be replayed under a
+// different cookie name.
+func (keys CookieKeys) Sign(name, value string) (string, error) {
+	if len(keys.Signing) == 0 {
+		return "", fmt.Errorf("no signing keys configured")
+	}
+	payload := _cookieEncoding.EncodeToString([]byte(value))
+	signature := cookieMAC(keys.Signing[0], name, payload)
+	return payload + "." + _cookieEncoding.EncodeToString(signature), nil
+}
+
+// Verify checks the signature of a value produced by Sign and returns the
+// original cookie value.
+func (keys CookieKeys) Verify(name, signed string) (string, error) {
+	if len(keys.Signing) == 0 {
+		return "", fmt.Errorf("no signing keys configured")
+	}
+
+	payload, encodedSignature, found := strings.Cut(signed, ".")
+	if !found {
+		return "", fmt.Errorf("%w: %s is not signed", ErrInvalidCookie, name)
+	}
+	signature, err := _cookieEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s has a malformed signature", ErrInvalidCookie, name)
+	}
+
+	for _, key := range keys.Signing {
+		if !hmac.Equal(signature, cookieMAC(key, name, payload)) {
+			continue
+		}
+		value, err := _cookieEncoding.DecodeString(payload)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s has a malformed value", ErrInvalidCookie, name)
+		}
+		return string(value), nil
+	}
+	return "", fmt.Errorf("%w: %s has a bad signature", ErrInvalidCookie, name)
+}
+
+// Encrypt returns the encrypted form of the cookie value. The name of the
+// cookie is authenticated along with the value.
+func (keys CookieKeys) Encrypt(name, value string) (string, error) {
+	if len(keys.Encryption) == 0 {
+		return "", fmt.Errorf("no encryption keys configured")
+	}
+	aead, err := cookieAEAD(keys.Encryption[0])
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("failed to generate cookie nonce: %v", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(name))
+	return _cookieEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value produced by Encrypt and returns the original
+// cookie value.
+func (keys CookieKeys) Decrypt(name, encrypted string) (string, error) {
+	if len(keys.Encryption) == 0 {
+		return "", fmt.Errorf("no encryption keys configured")
+	}
+
+	sealed, err := _cookieEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s is not encrypted", ErrInvalidCookie, name)
+	}
+
+	for _, key := range keys.Encryption {
+		aead, err := cookieAEAD(key)
+		if err != nil {
+			return "", err
+		} else if len(sealed) < aead.NonceSize() {
+			break
+		}
+
+		nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+		if value, err := aead.Open(nil, nonce, ciphertext, []byte(name)); err == nil {
+			return string(value), nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s could not be decrypted", ErrInvalidCookie, name)
+}
+
+// Signed returns a copy of the cookie whose value is signed.
+func (keys CookieKeys) Signed(cookie *http.Cookie) (*http.Cookie, error) {
+	value, err := keys.Sign(cookie.Name, cookie.Value)
+	if err != nil {
+		return nil, err
+	}
+	signed := *cookie
+	signed.Value = value
+	return &signed, nil
+}
+
+// Encrypted returns a copy of the cookie whose value is encrypted.
+func (keys CookieKeys) Encrypted(cookie *http.Cookie) (*http.Cookie, error) {
+	value, err := keys.Encrypt(cookie.Name, cookie.Value)
+	if err != nil {
+		return nil, err
+	}
+	encrypted := *cookie
+	encrypted.Value = value
+	return &encrypted, nil
+}
+
+func cookieMAC(key []byte, name, payload string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(name + "=" + payload))
+	return mac.Sum(nil)
+}
+
+func cookieAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("bad cookie encryption key: %v", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// cookieResponse adds cookies to an HTTPResponse.
+type cookieResponse struct {
+	HTTPResponse
+	cookies []*http.Cookie
+}
+
+// WithCookies returns an HTTPResponse that sets the given cookies on the
+// client along with the response. If the response is not already an
+// HTTPResponse, it is sent as JSON with a 200 OK. Signed and encrypted
+// cookies can be produced with CookieKeys.Signed and CookieKeys.Encrypted.
+func WithCookies(res any, cookies ...*http.Cookie) HTTPResponse {
+	cast, ok := res.(HTTPResponse)
+	if !ok {
+		cast = NewJSONResponse(http.StatusOK, res)
+	}
+	return cookieResponse{
+		HTTPResponse: cast,
+		cookies:      cookies,
+	}
+}
+
+func (res cookieResponse) Headers() http.Header {
+	headers := http.Header{}
+	if cast, ok := res.HTTPResponse.(HTTPHeaders); ok {
+		headers = cast.Headers().Clone()
+	}
+	for _, cookie := range res.cookies {
+		if encoded := cookie.String(); encoded != "" {
+			headers.Add("Set-Cookie", encoded)
+		}
+	}
+	return headers
+}
@@ -0,0 +1,114 @@
+package httpwrap
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCookieKeys(t *testing.T) {
+	oldKeys := CookieKeys{
+		Signing:    [][]byte{[]byte("old-signing-key")},
+		Encryption: [][]byte{[]byte("0123456789abcdef")},
+	}
+	newKeys := CookieKeys{
+		Signing:    [][]byte{[]byte("new-signing-key"), []byte("old-signing-key")},
+		Encryption: [][]byte{[]byte("fedcba9876543210"), []byte("0123456789abcdef")},
+	}
+
+	t.Run("signed with rotation", func(t *testing.T) {
+		signed, err := oldKeys.Sign("session", "user1")
+		require.NoError(t, err)
+
+		val, err := newKeys.Verify("session", signed)
+		require.NoError(t, err)
+		require.Equal(t, "user1", val)
+
+		_, err = newKeys.Verify("other", signed)
+		require.True(t, errors.Is(err, ErrInvalidCookie))
+
+		signed, err = newKeys.Sign("session", "user1")
+		require.NoError(t, err)
+		_, err = oldKeys.Verify("session", signed)
+		require.True(t, errors.Is(err, ErrInvalidCookie))
+	})
+
+	t.Run("encrypted with rotation", func(t *testing.T) {
+		encrypted, err := oldKeys.Encrypt("session", "user1")
+		require.NoError(t, err)
+		require.NotContains(t, encrypted, "user1")
+
+		val, err := newKeys.Decrypt("session", encrypted)
+		require.NoError(t, err)
+		require.Equal(t, "user1", val)
+
+		_, err = newKeys.Decrypt("other", encrypted)
+		require.True(t, errors.Is(err, ErrInvalidCookie))
+	})
+
+	t.Run("no keys", func(t *testing.T) {
+		_, err := CookieKeys{}.Sign("session", "user1")
+		require.Error(t, err)
+		_, err = CookieKeys{}.Encrypt("session", "user1")
+		require.Error(t, err)
+	})
+}
+
+func TestSecureCookies(t *testing.T) {
+	keys := CookieKeys{
+		Signing:    [][]byte{[]byte("signing-key")},
+		Encryption: [][]byte{[]byte("0123456789abcdef")},
+	}
+
+	type params struct {
+		Session string `http:"cookie=session,signed"`
+		Secret  string `http:"cookie=secret,encrypted"`
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		session, err := keys.Signed(&http.Cookie{Name: "session", Value: "user1"})
+		require.NoError(t, err)
+		secret, err := keys.Encrypted(&http.Cookie{Name: "secret", Value: "42"})
+		require.NoError(t, err)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/login", nil)
+		handler := NewStandardWrapper().Wrap(func() HTTPResponse {
+			return WithCookies(typedResponse{Value: 1}, session, secret)
+		})
+		handler.ServeHTTP(rw, req)
+		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+		require.Len(t, rw.Result().Cookies(), 2)
+
+		req = httptest.NewRequest("GET", "/me", nil)
+		for _, cookie := range rw.Result().Cookies() {
+			req.AddCookie(cookie)
+		}
+		into := params{}
+		require.NoError(t, NewDecoder().WithCookieKeys(keys).Decode(req, &into))
+		require.Equal(t, params{Session: "user1", Secret: "42"}, into)
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		session, err := keys.Signed(&http.Cookie{Name: "session", Value: "user1"})
+		require.NoError(t, err)
+		session.Value = "dXNlcjI" + session.Value[len("dXNlcjE"):]
+
+		req := httptest.NewRequest("GET", "/me", nil)
+		req.AddCookie(session)
+		err = NewDecoder().WithCookieKeys(keys).Decode(req, &params{})
+		httpErr, ok := err.(HTTPError)
+		require.True(t, ok)
+		require.Equal(t, http.StatusUnauthorized, httpErr.StatusCode())
+
+		req = httptest.NewRequest("GET", "/me", nil)
+		req.AddCookie(&http.Cookie{Name: "secret", Value: "not-encrypted"})
+		err = NewDecoder().WithCookieKeys(keys).Decode(req, &params{})
+		httpErr, ok = err.(HTTPError)
+		require.True(t, ok)
+		require.Equal(t, http.StatusUnauthorized, httpErr.StatusCode())
+	})
+}
@@ -1,6 +1,7 @@
 package httpwrap
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"reflect"
@@ -20,7 +21,9 @@ type Decoder struct {
 	Header func(*http.Request, string) (string, error)
 
 	// Segment is the function used to get the string value of a path
-	// parameter.
+	// parameter. By default, it reads the segments matched by the
+	// standard library's http.ServeMux; use WithSegmentResolver to
+	// support other routers.
 	Segment func(*http.Request, string) (string, error)
 
 	// Queries is the function used to get the string values of a query
@@ -30,8 +33,43 @@ type Decoder struct {
 	// Cookie is the function used to get the value of a cookie from a
 	// request.
 	Cookie func(*http.Request, string) (string, error)
+
+	// BasicAuth is the function used to get the user and password of
+	// the Basic credentials sent with a request.
+	BasicAuth func(*http.Request) (string, string, error)
+
+	// Bearer is the function used to get the Bearer token sent with a
+	// request.
+	Bearer func(*http.Request) (string, error)
+
+	// CookieKeys holds the keys used to read the cookies tagged as
+	// signed or encrypted.
+	CookieKeys CookieKeys
+
+	// AuthRealm is the realm advertised in the WWW-Authenticate header
+	// when the credentials of a request are malformed.
+	AuthRealm string
+}
+
+// DecodeError is the error returned by the Decoder when the content of
+// the request cannot be decoded into the target object. Source is the
+// part of the request that failed to decode (e.g: query or body) and Key
+// the name of the value within that source, if any.
+type DecodeError struct {
+	Source string
+	Key    string
+	Err    error
 }
 
+func (err *DecodeError) Error() string {
+	if err.Key == "" {
+		return fmt.Sprintf("failed to decode request %s: %v", err.Source, err.Err)
+	}
+	return fmt.Sprintf("failed to decode request %s %q: %v", err.Source, err.Key, err.Err)
+}
+
+func (err *DecodeError) Unwrap() error { return err.Err }
+
 // DecodeFunc is the function signature for decoding a request into an
 // object.
 type DecodeFunc func(req *http.Request, obj any) error
@@ -46,6 +84,31 @@ func NewDecoder() *Decoder {
 		Segment:    defaults.GetSegment,
 		Queries:    defaults.GetQueries,
 		Cookie:     defaults.GetCookie,
+		BasicAuth:  defaults.GetBasicAuth,
+		Bearer:     defaults.GetBearerToken,
+		AuthRealm:  "restricted",
+	}
+}
+
+// WithSegmentResolver sets the resolver used to read path segments
+// from the request and returns the decoder.
+func (d *Decoder) WithSegmentResolver(resolver defaults.SegmentResolver) *Decoder {
+	d.Segment = resolver.Segment
+	return d
+}
+
+// WithCookieKeys sets the keys used to verify signed cookies and decrypt
+// encrypted cookies, and returns the decoder.
+func (d *Decoder) WithCookieKeys(keys CookieKeys) *Decoder {
+	d.CookieKeys = keys
+	return d
+}
+
+// RequestReader returns a RequestReader that uses this decoder to
+// construct the endpoint parameter objects.
+func (d *Decoder) RequestReader() RequestReader {
+	return func(_ http.ResponseWriter, req *http.Request, obj any) error {
+		return d.Decode(req, obj)
 	}
 }
 
@@ -56,6 +119,10 @@ func NewDecoder() *Decoder {
 //			Limit int            `http:"query=limit"`
 //			Resource string      `http:"segment=resource"`
 //			UserCookie float64   `http:"cookie=user_cookie"`
+//			Session string       `http:"cookie=session,signed"`
+//			User string          `http:"basicauth=user"`
+//			Password string      `http:"basicauth=password"`
+//			Token string         `http:"bearer"`
 //			Extra map[string]int `json:"extra"`
 //	}
 //
@@ -66,10 +133,27 @@ func NewDecoder() *Decoder {
 //
 // The Resource field will come from the resource value of the path (e.g: /api/pets/{resource}).
 //
-// The Extra field will come from deserializing the request body from JSON encoding.
+// The Session field will come from the session cookie once its signature has
+// been verified with the CookieKeys of the decoder. Cookies tagged with the
+// `encrypted` option are decrypted instead. Tampered cookies result in a 401
+// HTTPError.
+//
+// The User and Password fields will come from the Basic credentials of the
+// Authorization header, and the Token field from its Bearer token. Malformed
+// credentials result in a 401 HTTPError carrying a WWW-Authenticate header.
+//
+// The Extra field will come from deserializing the request body from JSON
+// encoding. Bodies with a Content-Encoding other than gzip or deflate result
+// in a 415 HTTPError.
 func (d *Decoder) Decode(req *http.Request, obj any) error {
-	if err := d.DecodeBody(req, obj); err != nil {
-		return err
+	if err := d.DecodeBody(req, obj); errors.Is(err, defaults.ErrUnsupportedEncoding) {
+		return httpError{
+			code:    http.StatusUnsupportedMediaType,
+			body:    "Unsupported content encoding.",
+			headers: http.Header{"Accept-Encoding": []string{"gzip, deflate"}},
+		}
+	} else if err != nil {
+		return &DecodeError{Source: "body", Err: err}
 	}
 
 	v, valid := defaults.DerefValue(obj)
@@ -104,16 +188,35 @@ func (d *Decoder) Decode(req *http.Request, obj any) error {
 }
 
 func (d *Decoder) decodeDirective(req *http.Request, field reflect.Value, directive string) error {
-	split := strings.SplitN(directive, "=", 2)
-	if len(split) != 2 {
+	tagkey, tagval, found := strings.Cut(directive, "=")
+	if !found && tagkey != "bearer" {
 		return fmt.Errorf("malformed http struct tag: %v", directive)
 	}
-
-	tagkey, tagval := split[0], split[1]
 	return d.decodeValue(req, field, tagkey, tagval)
 }
 
 func (d *Decoder) decodeValue(req *http.Request, field reflect.Value, tagkey, tagval string) error {
+	strvals, err := d.Values(req, tagkey, tagval)
+	if err != nil || len(strvals) == 0 {
+		return err
+	}
+
+	val, err := defaults.GenVal(field.Type(), strvals[0], strvals[1:]...)
+	if err != nil {
+		key, _, _ := strings.Cut(tagval, ",")
+		return &DecodeError{Source: tagkey, Key: key, Err: err}
+	}
+
+	field.Set(val)
+	return nil
+}
+
+// Values returns the string values of the request designated by the key and
+// value of an http tag, e.g: the values of the limit query parameter for
+// `http:"query=limit"`. It returns no values when the request does not have
+// them, and a 401 HTTPError when the credentials of the request are
+// malformed.
+func (d *Decoder) Values(req *http.Request, tagkey, tagval string) ([]string, error) {
 	strvals := []string{""}
 	var err error
 
@@ -123,28 +226,81 @@ func (d *Decoder) decodeValue(req *http.Request, field reflect.Value, tagkey, ta
 	case "segment":
 		strvals[0], err = d.Segment(req, tagval)
 	case "cookie":
-		strvals[0], err = d.Cookie(req, tagval)
+		strvals[0], err = d.cookie(req, tagval)
 	case "query":
 		strvals, err = d.Queries(req, tagval)
+	case "basicauth":
+		strvals[0], err = d.basicAuth(req, tagval)
+	case "bearer":
+		strvals[0], err = d.Bearer(req)
 	default:
-		return fmt.Errorf("unrecognized http tag %v", tagkey)
+		return nil, fmt.Errorf("unrecognized http tag %v", tagkey)
+	}
+
+	if errors.Is(err, defaults.ErrMalformedCredentials) {
+		return nil, d.unauthorized(tagkey)
 	}
 
 	if len(strvals) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	if err == defaults.ErrValueNotFound {
-		return nil
+		return nil, nil
 	} else if err != nil {
-		return err
+		return nil, err
 	}
+	return strvals, nil
+}
 
-	val, err := defaults.GenVal(field.Type(), strvals[0], strvals[1:]...)
+func (d *Decoder) cookie(req *http.Request, tagval string) (string, error) {
+	name, option, _ := strings.Cut(tagval, ",")
+	val, err := d.Cookie(req, name)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	field.Set(val)
-	return nil
+	switch option {
+	case "":
+		return val, nil
+	case "signed":
+		val, err = d.CookieKeys.Verify(name, val)
+	case "encrypted":
+		val, err = d.CookieKeys.Decrypt(name, val)
+	default:
+		return "", fmt.Errorf("unrecognized cookie option %v", option)
+	}
+
+	if errors.Is(err, ErrInvalidCookie) {
+		return "", NewHTTPError(http.StatusUnauthorized, "Invalid cookie.")
+	}
+	return val, err
+}
+
+func (d *Decoder) basicAuth(req *http.Request, part string) (string, error) {
+	if part != "user" && part != "password" {
+		return "", fmt.Errorf("unrecognized basicauth value %v", part)
+	}
+
+	user, password, err := d.BasicAuth(req)
+	if err != nil {
+		return "", err
+	} else if part == "user" {
+		return user, nil
+	}
+	return password, nil
+}
+
+// unauthorized returns the 401 error sent back when the credentials
+// expected by the http tag are malformed.
+func (d *Decoder) unauthorized(tagkey string) HTTPError {
+	challenge := fmt.Sprintf(`Basic realm=%q, charset="UTF-8"`, d.AuthRealm)
+	if tagkey == "bearer" {
+		challenge = fmt.Sprintf(`Bearer realm=%q, error="invalid_request"`, d.AuthRealm)
+	}
+	return httpError{
+		code:    http.StatusUnauthorized,
+		body:    "Malformed credentials.",
+		headers: http.Header{"Www-Authenticate": []string{challenge}},
+	}
 }
@@ -1,13 +1,17 @@
 package httpwrap
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
-	"github.com/gorilla/mux"
+	"github.com/apourchet/httpwrap/defaults"
 	"github.com/stretchr/testify/require"
 )
 
@@ -47,29 +51,150 @@ func TestDecoder(t *testing.T) {
 	})
 }
 
-func TestDecoderGorillaMux(t *testing.T) {
-	t.Run("decode full", func(t *testing.T) {
-		body := strings.NewReader(`{"body1":42}`)
-		url := fmt.Sprintf("http://localhost/path?query1=query1val&query2=1&query2=2")
-		req := httptest.NewRequest("POST", url, body)
-		req.Header.Set("header1", "header1val")
-		req.AddCookie(&http.Cookie{Name: "cookie1", Value: "true"})
-		req = mux.SetURLVars(req, map[string]string{"segment1": "segment1val"})
+func TestDecoderSegments(t *testing.T) {
+	type params struct {
+		Name string `http:"segment=name"`
+	}
 
-		decoder := NewDecoder()
-		into := holder{}
-		err := decoder.Decode(req, &into)
-		require.NoError(t, err)
+	t.Run("servemux pattern", func(t *testing.T) {
+		var into params
+		var decodeErr error
+		router := http.NewServeMux()
+		router.HandleFunc("GET /pets/{name}", func(_ http.ResponseWriter, req *http.Request) {
+			decodeErr = NewDecoder().Decode(req, &into)
+		})
 
-		_headerVal := "header1val"
-		expected := holder{
-			Body1:    42,
-			Header1:  &_headerVal,
-			Segment1: "segment1val",
-			Cookie1:  true,
-			Query1:   "query1val",
-			Query2:   []int{1, 2},
-		}
-		require.Equal(t, expected, into)
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/pets/rex", nil))
+		require.NoError(t, decodeErr)
+		require.Equal(t, "rex", into.Name)
+	})
+
+	t.Run("not routed by a servemux", func(t *testing.T) {
+		// e.g: a gorilla/mux router without the gorillamux resolver.
+		var decodeErr error
+		router := http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+			decodeErr = NewDecoder().Decode(req, &params{})
+		})
+
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/pets/rex", nil))
+		require.True(t, errors.Is(decodeErr, defaults.ErrRequestNotRouted))
+
+		rw := httptest.NewRecorder()
+		NewStandardWrapper().Wrap(func(params) {}).ServeHTTP(rw, httptest.NewRequest("GET", "/pets/rex", nil))
+		require.Equal(t, http.StatusInternalServerError, rw.Code)
 	})
+
+	t.Run("servemux segment not in pattern", func(t *testing.T) {
+		var decodeErr error
+		router := http.NewServeMux()
+		router.HandleFunc("GET /pets/{id}", func(_ http.ResponseWriter, req *http.Request) {
+			decodeErr = NewDecoder().Decode(req, &params{})
+		})
+
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/pets/rex", nil))
+		require.True(t, errors.Is(decodeErr, defaults.ErrSegmentNotInRoute))
+	})
+
+	t.Run("context segments", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/pets/rex", nil)
+		req = defaults.WithSegments(req, map[string]string{"name": "rex"})
+
+		into := params{}
+		decoder := NewDecoder().WithSegmentResolver(defaults.ContextSegments(nil))
+		require.NoError(t, decoder.Decode(req, &into))
+		require.Equal(t, "rex", into.Name)
+
+		req = defaults.WithSegments(req, map[string]string{"id": "rex"})
+		err := decoder.Decode(req, &params{})
+		require.True(t, errors.Is(err, defaults.ErrSegmentNotInRoute))
+	})
+
+	t.Run("custom context key", func(t *testing.T) {
+		type routeVars struct{}
+		req := httptest.NewRequest("GET", "/pets/rex", nil)
+		req = req.WithContext(context.WithValue(req.Context(), routeVars{}, map[string]string{"name": "rex"}))
+
+		into := params{}
+		decoder := NewDecoder().WithSegmentResolver(defaults.ContextSegments(routeVars{}))
+		require.NoError(t, decoder.Decode(req, &into))
+		require.Equal(t, "rex", into.Name)
+	})
+}
+
+func TestDecoderCredentials(t *testing.T) {
+	type basic struct {
+		User     string `http:"basicauth=user"`
+		Password string `http:"basicauth=password"`
+	}
+	type bearer struct {
+		Token string `http:"bearer"`
+	}
+
+	t.Run("basic auth", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/path", nil)
+		req.SetBasicAuth("user1", "secret")
+
+		into := basic{}
+		require.NoError(t, NewDecoder().Decode(req, &into))
+		require.Equal(t, basic{User: "user1", Password: "secret"}, into)
+	})
+
+	t.Run("bearer token", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/path", nil)
+		req.Header.Set("Authorization", "Bearer abc.def")
+
+		into := bearer{}
+		require.NoError(t, NewDecoder().Decode(req, &into))
+		require.Equal(t, "abc.def", into.Token)
+	})
+
+	t.Run("missing credentials", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/path", nil)
+		require.NoError(t, NewDecoder().Decode(req, &basic{}))
+		require.NoError(t, NewDecoder().Decode(req, &bearer{}))
+	})
+
+	t.Run("malformed scheme", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/path", nil)
+		req.Header.Set("Authorization", "Token abc")
+
+		err := NewDecoder().Decode(req, &basic{})
+		httpErr, ok := err.(HTTPError)
+		require.True(t, ok)
+		require.Equal(t, http.StatusUnauthorized, httpErr.StatusCode())
+		require.Equal(t, `Basic realm="restricted", charset="UTF-8"`, httpErr.(HTTPHeaders).Headers().Get("WWW-Authenticate"))
+
+		err = NewDecoder().Decode(req, &bearer{})
+		httpErr, ok = err.(HTTPError)
+		require.True(t, ok)
+		require.Equal(t, http.StatusUnauthorized, httpErr.StatusCode())
+		require.Equal(t, `Bearer realm="restricted", error="invalid_request"`, httpErr.(HTTPHeaders).Headers().Get("WWW-Authenticate"))
+	})
+}
+
+func TestDecoderCompressedBody(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer := gzip.NewWriter(buf)
+	_, err := writer.Write([]byte(`{"body1":42}`))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest("POST", "/path", buf)
+	req.Header.Set("Content-Encoding", "gzip")
+	req.SetPathValue("segment1", "segment1val")
+
+	into := holder{}
+	require.NoError(t, NewDecoder().Decode(req, &into))
+	require.Equal(t, 42, into.Body1)
+}
+
+func TestDecoderUnsupportedEncoding(t *testing.T) {
+	req := httptest.NewRequest("POST", "/path", strings.NewReader(`{"body1":42}`))
+	req.Header.Set("Content-Encoding", "br")
+
+	into := holder{}
+	err := NewDecoder().Decode(req, &into)
+	var httpErr HTTPError
+	require.True(t, errors.As(err, &httpErr))
+	require.Equal(t, http.StatusUnsupportedMediaType, httpErr.StatusCode())
 }
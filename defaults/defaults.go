@@ -2,34 +2,66 @@ package defaults
 
 import (
 	"bytes"
+	"compress/gzip"
+	"compress/zlib"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-
-	"github.com/gorilla/mux"
+	"strings"
 )
 
 var (
 	// ErrValueNotFound is the error returned from the Get* functions
 	// when this value was not found in the request.
 	ErrValueNotFound = errors.New("value not found")
+
+	// ErrMalformedCredentials is the error returned from the credential
+	// getters when the Authorization header does not follow the
+	// expected scheme.
+	ErrMalformedCredentials = errors.New("malformed credentials")
+
+	// ErrUnsupportedEncoding is the error returned from DecodeBody when
+	// the Content-Encoding of the request is neither gzip nor deflate.
+	ErrUnsupportedEncoding = errors.New("unsupported content encoding")
 )
 
 // DecodeBody uses a json decoder to decode the body of the request
-// into the target object.
+// into the target object. Bodies with a gzip or deflate Content-Encoding
+// are decompressed first.
 func DecodeBody(req *http.Request, obj any) error {
 	buf := &bytes.Buffer{}
 	defer func() { req.Body = io.NopCloser(buf) }()
-	err := json.NewDecoder(io.TeeReader(req.Body, buf)).Decode(obj)
+	body, err := decompress(req, io.TeeReader(req.Body, buf))
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	err = json.NewDecoder(body).Decode(obj)
 	if err == io.EOF {
 		return nil
 	}
 	return err
 }
 
+// decompress returns the reader of the decompressed body according to the
+// Content-Encoding of the request.
+func decompress(req *http.Request, body io.Reader) (io.Reader, error) {
+	switch strings.ToLower(req.Header.Get("Content-Encoding")) {
+	case "", "identity":
+		return body, nil
+	case "gzip", "x-gzip":
+		return gzip.NewReader(body)
+	case "deflate":
+		return zlib.NewReader(body)
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnsupportedEncoding, req.Header.Get("Content-Encoding"))
+}
+
 // GetHeader returns the value of the header if it was found.
 func GetHeader(req *http.Request, key string) (string, error) {
 	val := req.Header.Get(key)
@@ -39,24 +71,6 @@ func GetHeader(req *http.Request, key string) (string, error) {
 	return val, nil
 }
 
-// GetSegment returns ErrValueNotFound because we have no way of
-// knowing how the server mux has altered the request to let this
-// information resurface.
-func GetSegment(req *http.Request, key string) (string, error) {
-	if segmentVal := req.PathValue(key); segmentVal != "" {
-		return segmentVal, nil
-	}
-
-	// We need some insight into the parsing of the request path if we
-	// want access to this information. Therefore, we expect this to be
-	// user-supplied. As a default, we use gorilla's mux package to
-	// fetch the right variables.
-	if segmentVal := mux.Vars(req)[key]; segmentVal != "" {
-		return segmentVal, nil
-	}
-	return "", ErrValueNotFound
-}
-
 // GetQueries returns the list of values that this query parameter
 // had in the request.
 func GetQueries(req *http.Request, key string) ([]string, error) {
@@ -90,3 +104,35 @@ func GetCookie(req *http.Request, key string) (string, error) {
 	}
 	return val, nil
 }
+
+// GetBasicAuth returns the user and password sent in the Authorization
+// header of the request using the Basic scheme. It returns an error
+// wrapping ErrMalformedCredentials if the header is present but does
+// not hold valid Basic credentials.
+func GetBasicAuth(req *http.Request) (string, string, error) {
+	if req.Header.Get("Authorization") == "" {
+		return "", "", ErrValueNotFound
+	}
+	user, password, ok := req.BasicAuth()
+	if !ok {
+		return "", "", fmt.Errorf("%w: expected Basic credentials", ErrMalformedCredentials)
+	}
+	return user, password, nil
+}
+
+// GetBearerToken returns the token sent in the Authorization header of
+// the request using the Bearer scheme. It returns an error wrapping
+// ErrMalformedCredentials if the header is present but does not hold
+// a Bearer token.
+func GetBearerToken(req *http.Request) (string, error) {
+	header := req.Header.Get("Authorization")
+	if header == "" {
+		return "", ErrValueNotFound
+	}
+	scheme, token, found := strings.Cut(header, " ")
+	token = strings.TrimSpace(token)
+	if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", fmt.Errorf("%w: expected Bearer token", ErrMalformedCredentials)
+	}
+	return token, nil
+}
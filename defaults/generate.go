@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 // GenVal generates an any from the string values given.
@@ -42,3 +43,151 @@ func genVals(t reflect.Type, values []string) (reflect.Value, error) {
 	}
 	return val.Elem(), nil
 }
+
+// Gen sets dst to the value generated from the string values given, the
+// same way as GenVal. Strings, booleans and numbers are parsed without
+// reflection, the other types go through GenVal.
+func Gen[T any](dst *T, value string, values ...string) error {
+	if len(values) == 0 && genBasic(dst, value) {
+		return nil
+	}
+
+	val, err := GenVal(reflect.TypeOf(dst).Elem(), value, values...)
+	if err != nil {
+		return err
+	}
+	*dst = val.Interface().(T)
+	return nil
+}
+
+// genBasic parses the value into dst when dst points to a string, a boolean
+// or a number and the value is parsed the same way by GenVal. It returns
+// false when the value should go through GenVal instead.
+func genBasic(dst any, value string) bool {
+	switch dst := dst.(type) {
+	case *string:
+		if !isPlainString(value) {
+			return false
+		}
+		*dst = value
+	case *bool:
+		if value != "true" && value != "false" {
+			return false
+		}
+		*dst = value == "true"
+	case *int:
+		return genInt(dst, value, strconv.IntSize)
+	case *int8:
+		return genInt(dst, value, 8)
+	case *int16:
+		return genInt(dst, value, 16)
+	case *int32:
+		return genInt(dst, value, 32)
+	case *int64:
+		return genInt(dst, value, 64)
+	case *uint:
+		return genUint(dst, value, strconv.IntSize)
+	case *uint8:
+		return genUint(dst, value, 8)
+	case *uint16:
+		return genUint(dst, value, 16)
+	case *uint32:
+		return genUint(dst, value, 32)
+	case *uint64:
+		return genUint(dst, value, 64)
+	case *float32:
+		return genFloat(dst, value, 32)
+	case *float64:
+		return genFloat(dst, value, 64)
+	default:
+		return false
+	}
+	return true
+}
+
+func genInt[T int | int8 | int16 | int32 | int64](dst *T, value string, bitSize int) bool {
+	if !isJSONInteger(value) {
+		return false
+	}
+	i, err := strconv.ParseInt(value, 10, bitSize)
+	if err != nil {
+		return false
+	}
+	*dst = T(i)
+	return true
+}
+
+func genUint[T uint | uint8 | uint16 | uint32 | uint64](dst *T, value string, bitSize int) bool {
+	if !isJSONInteger(value) {
+		return false
+	}
+	u, err := strconv.ParseUint(value, 10, bitSize)
+	if err != nil {
+		return false
+	}
+	*dst = T(u)
+	return true
+}
+
+func genFloat[T float32 | float64](dst *T, value string, bitSize int) bool {
+	if !isJSONNumber(value) {
+		return false
+	}
+	f, err := strconv.ParseFloat(value, bitSize)
+	if err != nil {
+		return false
+	}
+	*dst = T(f)
+	return true
+}
+
+// isPlainString returns whether GenVal keeps the value as it is, which is
+// the case when it is not a JSON string literal or null and quoting it
+// yields a valid JSON string.
+func isPlainString(value string) bool {
+	trimmed := strings.TrimSpace(value)
+	if strings.HasPrefix(trimmed, `"`) || trimmed == "null" || !utf8.ValidString(value) {
+		return false
+	}
+	for _, r := range value {
+		if !strconv.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// isJSONInteger returns whether the value is an integer in JSON syntax.
+func isJSONInteger(value string) bool {
+	digits := strings.TrimPrefix(value, "-")
+	return isDigits(digits) && (len(digits) == 1 || digits[0] != '0')
+}
+
+// isJSONNumber returns whether the value is a number in JSON syntax.
+func isJSONNumber(value string) bool {
+	mantissa, exponent, hasExponent := strings.Cut(strings.ToLower(value), "e")
+	integer, fraction, hasFraction := strings.Cut(mantissa, ".")
+	if !isJSONInteger(integer) || (hasFraction && !isDigits(fraction)) {
+		return false
+	}
+	if hasExponent {
+		if len(exponent) > 0 && (exponent[0] == '+' || exponent[0] == '-') {
+			exponent = exponent[1:]
+		}
+		return isDigits(exponent)
+	}
+	return true
+}
+
+// isDigits returns whether the value is a non-empty sequence of digits.
+func isDigits(value string) bool {
+	if value == "" {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		if value[i] < '0' || value[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
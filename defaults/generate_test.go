@@ -88,3 +88,60 @@ func TestGenVal(t *testing.T) {
 		require.Equal(t, []string{"a", "b"}, *into)
 	})
 }
+
+func TestGen(t *testing.T) {
+	values := []string{
+		"", "test", " test ", `"quoted"`, ` "quoted"`, "null", " null ", "a\tb", "\xff", `back\slash`, `say "hi"`,
+		"true", "false", "True", "0", "-0", "1", "-1", "01", "+1", "127", "128", "-129", "255", "256", "-1.5",
+		"1.5", "1.", ".5", "1e3", "1E+3", "1e-3", "1e", "1e+-3", "1e400", "3.4e39", "18446744073709551615",
+		"9223372036854775808", "0x10", "[1]", "{}",
+	}
+
+	// Gen must generate the same values and failures as GenVal.
+	check := func(t *testing.T, gen func(string) (any, error), into any) {
+		for _, value := range values {
+			expected, expectedErr := GenVal(reflect.TypeOf(into), value)
+			actual, err := gen(value)
+			if expectedErr != nil {
+				require.Error(t, err, "value %q", value)
+				continue
+			}
+			require.NoError(t, err, "value %q", value)
+			require.Equal(t, expected.Interface(), actual, "value %q", value)
+		}
+	}
+
+	t.Run("string", func(t *testing.T) {
+		check(t, func(value string) (any, error) { var v string; err := Gen(&v, value); return v, err }, "")
+	})
+	t.Run("bool", func(t *testing.T) {
+		check(t, func(value string) (any, error) { var v bool; err := Gen(&v, value); return v, err }, false)
+	})
+	t.Run("int", func(t *testing.T) {
+		check(t, func(value string) (any, error) { var v int; err := Gen(&v, value); return v, err }, 0)
+	})
+	t.Run("int8", func(t *testing.T) {
+		check(t, func(value string) (any, error) { var v int8; err := Gen(&v, value); return v, err }, int8(0))
+	})
+	t.Run("uint8", func(t *testing.T) {
+		check(t, func(value string) (any, error) { var v uint8; err := Gen(&v, value); return v, err }, uint8(0))
+	})
+	t.Run("uint64", func(t *testing.T) {
+		check(t, func(value string) (any, error) { var v uint64; err := Gen(&v, value); return v, err }, uint64(0))
+	})
+	t.Run("float32", func(t *testing.T) {
+		check(t, func(value string) (any, error) { var v float32; err := Gen(&v, value); return v, err }, float32(0))
+	})
+	t.Run("float64", func(t *testing.T) {
+		check(t, func(value string) (any, error) { var v float64; err := Gen(&v, value); return v, err }, float64(0))
+	})
+
+	t.Run("other types go through GenVal", func(t *testing.T) {
+		var into *[]int
+		require.NoError(t, Gen(&into, "1", "2"))
+		require.Equal(t, []int{1, 2}, *into)
+
+		var status int
+		require.Error(t, Gen(&status, "1", "2"))
+	})
+}
@@ -0,0 +1,14 @@
+module github.com/apourchet/httpwrap/defaults/gorillamux
+
+go 1.24.0
+
+require (
+	github.com/apourchet/httpwrap v0.0.0-20261019164244-0d5e872cfda9
+	github.com/gorilla/mux v1.7.2
+	github.com/stretchr/testify v1.3.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+)
@@ -0,0 +1,35 @@
+// Package gorillamux provides the SegmentResolver for requests routed
+// by github.com/gorilla/mux. It lives in its own module so that only
+// the users of that router depend on it.
+package gorillamux
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/apourchet/httpwrap/defaults"
+	"github.com/gorilla/mux"
+)
+
+// Segments returns the SegmentResolver for requests routed by
+// gorilla/mux.
+func Segments() defaults.SegmentResolver {
+	return defaults.SegmentResolverFunc(GetSegment)
+}
+
+// GetSegment returns the value of the path segment as matched by
+// gorilla/mux. Segments that are not part of the matched route are
+// reported with defaults.ErrSegmentNotInRoute.
+func GetSegment(req *http.Request, key string) (string, error) {
+	vars := mux.Vars(req)
+	if vars == nil {
+		return "", defaults.ErrValueNotFound
+	}
+	val, found := vars[key]
+	if !found {
+		return "", fmt.Errorf("%w: %q", defaults.ErrSegmentNotInRoute, key)
+	} else if val == "" {
+		return "", defaults.ErrValueNotFound
+	}
+	return val, nil
+}
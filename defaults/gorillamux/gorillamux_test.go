@@ -0,0 +1,49 @@
+package gorillamux
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/apourchet/httpwrap"
+	"github.com/apourchet/httpwrap/defaults"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+type params struct {
+	Name  string `http:"segment=name"`
+	Limit int    `http:"query=limit"`
+}
+
+func TestSegments(t *testing.T) {
+	decoder := httpwrap.NewDecoder().WithSegmentResolver(Segments())
+
+	t.Run("router", func(t *testing.T) {
+		var into params
+		var decodeErr error
+		router := mux.NewRouter()
+		router.HandleFunc("/pets/{name}", func(_ http.ResponseWriter, req *http.Request) {
+			decodeErr = decoder.Decode(req, &into)
+		})
+
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/pets/rex?limit=2", nil))
+		require.NoError(t, decodeErr)
+		require.Equal(t, params{Name: "rex", Limit: 2}, into)
+	})
+
+	t.Run("segment not in route", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/pets/rex", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": "rex"})
+
+		err := decoder.Decode(req, &params{})
+		require.True(t, errors.Is(err, defaults.ErrSegmentNotInRoute))
+	})
+
+	t.Run("not routed", func(t *testing.T) {
+		into := params{}
+		require.NoError(t, decoder.Decode(httptest.NewRequest("GET", "/pets/rex", nil), &into))
+		require.Equal(t, "", into.Name)
+	})
+}
@@ -0,0 +1,119 @@
+package defaults
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrSegmentNotInRoute is the error returned by a SegmentResolver
+	// when the route that matched the request does not declare the
+	// requested path segment. This almost always points to a mismatch
+	// between the route pattern and the struct tags of the handler.
+	ErrSegmentNotInRoute = errors.New("segment not in matched route")
+
+	// ErrRequestNotRouted is the error returned by the SegmentResolver
+	// of the http.ServeMux when the request was not routed by a
+	// ServeMux, e.g: by gorilla/mux, whose routes need the resolver of
+	// the gorillamux module.
+	ErrRequestNotRouted = errors.New("request not routed by an http.ServeMux, set the SegmentResolver of the router")
+)
+
+// SegmentResolver knows how to read path segments (e.g: the `name`
+// in /pets/{name}) from a request that was routed by a specific
+// router.
+type SegmentResolver interface {
+	// Segment returns the value of the path segment. It returns
+	// ErrValueNotFound when the value is missing, and an error
+	// wrapping ErrSegmentNotInRoute when the matched route is known
+	// and does not contain that segment.
+	Segment(req *http.Request, key string) (string, error)
+}
+
+// SegmentResolverFunc adapts a plain function into a SegmentResolver.
+type SegmentResolverFunc func(req *http.Request, key string) (string, error)
+
+// Segment implements SegmentResolver.
+func (fn SegmentResolverFunc) Segment(req *http.Request, key string) (string, error) {
+	return fn(req, key)
+}
+
+// ServeMuxSegments returns the SegmentResolver for requests routed by
+// the standard library's http.ServeMux.
+func ServeMuxSegments() SegmentResolver {
+	return SegmentResolverFunc(GetSegment)
+}
+
+// GetSegment returns the value of the path segment as matched by
+// the standard library's http.ServeMux. If the request carries the
+// pattern it was matched against, segments that are not part of that
+// pattern are reported with ErrSegmentNotInRoute. Requests that carry
+// neither a pattern nor the value, which were not routed by a ServeMux,
+// are reported with ErrRequestNotRouted rather than decoded as empty.
+func GetSegment(req *http.Request, key string) (string, error) {
+	if req.Pattern != "" && !patternHasSegment(req.Pattern, key) {
+		return "", fmt.Errorf("%w: %q is not in %q", ErrSegmentNotInRoute, key, req.Pattern)
+	}
+	if segmentVal := req.PathValue(key); segmentVal != "" {
+		return segmentVal, nil
+	} else if req.Pattern == "" {
+		return "", fmt.Errorf("%w: segment %q", ErrRequestNotRouted, key)
+	}
+	return "", ErrValueNotFound
+}
+
+// patternHasSegment returns whether the ServeMux pattern declares a
+// wildcard with the given name, either as {key} or {key...}.
+func patternHasSegment(pattern, key string) bool {
+	for {
+		start := strings.IndexByte(pattern, '{')
+		if start < 0 {
+			return false
+		}
+		end := strings.IndexByte(pattern[start:], '}')
+		if end < 0 {
+			return false
+		}
+		name := strings.TrimSuffix(pattern[start+1:start+end], "...")
+		if name == key {
+			return true
+		}
+		pattern = pattern[start+end+1:]
+	}
+}
+
+type segmentsKey struct{}
+
+// WithSegments returns a shallow copy of the request whose context
+// carries the given path segments. Routers that are not natively
+// supported can use this to feed the ContextSegments resolver.
+func WithSegments(req *http.Request, segments map[string]string) *http.Request {
+	ctx := context.WithValue(req.Context(), segmentsKey{}, segments)
+	return req.WithContext(ctx)
+}
+
+// ContextSegments returns a SegmentResolver that reads the path
+// segments from a map[string]string stored in the request context
+// under the given key. A nil key reads the segments stored by
+// WithSegments.
+func ContextSegments(key any) SegmentResolver {
+	if key == nil {
+		key = segmentsKey{}
+	}
+	return SegmentResolverFunc(func(req *http.Request, name string) (string, error) {
+		segments, found := req.Context().Value(key).(map[string]string)
+		if !found {
+			return "", ErrValueNotFound
+		}
+		val, found := segments[name]
+		if !found {
+			return "", fmt.Errorf("%w: %q", ErrSegmentNotInRoute, name)
+		} else if val == "" {
+			return "", ErrValueNotFound
+		}
+		return val, nil
+	})
+}
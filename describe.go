@@ -0,0 +1,234 @@
+package httpwrap
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// StageKind is the kind of a stage of a Plan.
+type StageKind string
+
+const (
+	StageBefore  StageKind = "before"
+	StageMain    StageKind = "main"
+	StageFinally StageKind = "finally"
+)
+
+// InputSource is where the value of an input of a stage comes from.
+type InputSource string
+
+const (
+	// SourceRequest is the http request.
+	SourceRequest InputSource = "request"
+
+	// SourceResponseWriter is the response writer of the request, or the
+	// ResponseInfo read from it.
+	SourceResponseWriter InputSource = "response writer"
+
+	// SourceStage is an output of an earlier stage, e.g: a before.
+	SourceStage InputSource = "stage"
+
+	// SourceOverride is the value supplied with WithOverride.
+	SourceOverride InputSource = "override"
+
+	// SourceConstructor is a value constructed by the RequestReader of the
+	// wrapper.
+	SourceConstructor InputSource = "constructor"
+
+	// SourceZero is the zero value of the type.
+	SourceZero InputSource = "zero value"
+
+	// SourceResponse is the response returned by the main function, as
+	// given to the Finally function.
+	SourceResponse InputSource = "response"
+
+	// SourceError is the error of the stage that failed, if any, as given
+	// to the Finally function.
+	SourceError InputSource = "error"
+)
+
+// Plan describes how a Wrapper serves a request with a main function: the
+// stages that run in order, and where each of their inputs comes from.
+type Plan struct {
+	Stages []PlanStage
+}
+
+// PlanStage is a function run by the wrapper.
+type PlanStage struct {
+	Kind StageKind
+
+	// Name is the name of the function, e.g: main.checkAPICreds.
+	Name string
+
+	Inputs  []PlanInput
+	Outputs []reflect.Type
+}
+
+// PlanInput is an input of a stage.
+type PlanInput struct {
+	Type   reflect.Type
+	Source InputSource
+
+	// Stage is the index of the stage that provides the input in the
+	// stages of the plan, when the source is SourceStage or
+	// SourceResponse.
+	Stage int
+}
+
+// Describe returns the plan of the requests served by the main function
+// wrapped with the wrapper, without running anything. It panics if the main
+// function is invalid, like Wrap does. Values are only provided by the
+// stages that return them, e.g: a before returning a nil pointer leaves the
+// inputs of that type to their zero value.
+//
+//	fmt.Println(wrapper.Describe(GetPetByName))
+func (w Wrapper) Describe(fn any) Plan {
+	main, err := newMain(fn)
+	if err != nil {
+		panic(err)
+	}
+
+	plan := Plan{}
+	providers := []PlanInput{
+		{Type: _requestType, Source: SourceRequest},
+		{Type: reflect.TypeOf(&responseRecorder{}), Source: SourceResponseWriter},
+		{Type: reflect.TypeOf(ResponseInfo{}), Source: SourceResponseWriter},
+	}
+	describe := func(kind StageKind, val reflect.Value, inTypes, outTypes []reflect.Type) {
+		stage := PlanStage{Kind: kind, Name: funcName(val), Outputs: outTypes}
+		for _, t := range inTypes {
+			stage.Inputs = append(stage.Inputs, w.planInput(t, kind, len(w.befores), providers))
+		}
+		for _, t := range outTypes {
+			// Errors stop the chain, so the next stages never get them.
+			if !isError(t) {
+				providers = append(providers, PlanInput{Type: t, Source: SourceStage, Stage: len(plan.Stages)})
+			}
+		}
+		plan.Stages = append(plan.Stages, stage)
+	}
+
+	for _, before := range w.befores {
+		describe(StageBefore, before.val, before.inTypes, before.outTypes)
+	}
+	describe(StageMain, main.val, main.inTypes, main.outTypes)
+	if w.after != nil {
+		describe(StageFinally, w.after.val, w.after.inTypes, w.after.outTypes)
+	}
+	return plan
+}
+
+// planInput returns where the input of the type comes from, the same way
+// runctx.get and runctx.construct find it. The providers are the values
+// provided before the stage, in order.
+func (w Wrapper) planInput(t reflect.Type, kind StageKind, mainIndex int, providers []PlanInput) PlanInput {
+	if isEmptyInterface(t) {
+		return PlanInput{Type: t, Source: SourceResponse, Stage: mainIndex}
+	} else if kind == StageFinally && t == _errorType {
+		return PlanInput{Type: t, Source: SourceError}
+	} else if _, found := w.overrides.lookup(t); found {
+		return PlanInput{Type: t, Source: SourceOverride}
+	}
+
+	for i := len(providers) - 1; i >= 0; i-- {
+		p := providers[i]
+		if _, overridden := w.overrides.find(p.Type); overridden {
+			continue
+		}
+		if p.Type == t || (t.Kind() == reflect.Interface && p.Type.Implements(t)) {
+			return PlanInput{Type: t, Source: p.Source, Stage: p.Stage}
+		}
+	}
+
+	if t.Kind() == reflect.Interface || isEmptyRequestReader(w.construct) {
+		return PlanInput{Type: t, Source: SourceZero}
+	}
+	return PlanInput{Type: t, Source: SourceConstructor}
+}
+
+// String renders the plan as text, with one line per stage followed by one
+// line per input:
+//
+//	before #0 main.checkAPICreds
+//		main.APICredentials <- constructor
+//	main main.(*PetStoreHandler).GetPetByName
+//		main.GetByNameParams <- constructor
+func (p Plan) String() string {
+	var sb strings.Builder
+	for i, stage := range p.Stages {
+		fmt.Fprintf(&sb, "%s %s\n", p.stageLabel(i), stage.Name)
+		for _, in := range stage.Inputs {
+			fmt.Fprintf(&sb, "\t%v <- %s\n", in.Type, p.sourceLabel(in))
+		}
+	}
+	return sb.String()
+}
+
+// DOT renders the plan as a Graphviz graph, where the inputs are edges from
+// their sources to the stages that take them, e.g: to render it as an image
+// with `dot -Tsvg`.
+func (p Plan) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph plan {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	for i, stage := range p.Stages {
+		fmt.Fprintf(&sb, "\tstage%d [label=%s];\n", i, strconv.Quote(p.stageLabel(i)+"\n"+stage.Name))
+	}
+
+	sources := map[InputSource]bool{}
+	for i, stage := range p.Stages {
+		for _, in := range stage.Inputs {
+			from := "stage" + strconv.Itoa(in.Stage)
+			if in.Source != SourceStage && in.Source != SourceResponse {
+				from = strings.ReplaceAll(string(in.Source), " ", "_")
+				if !sources[in.Source] {
+					sources[in.Source] = true
+					fmt.Fprintf(&sb, "\t%s [label=%s, shape=ellipse];\n", from, strconv.Quote(string(in.Source)))
+				}
+			}
+			fmt.Fprintf(&sb, "\t%s -> stage%d [label=%s];\n", from, i, strconv.Quote(in.Type.String()))
+		}
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func (p Plan) stageLabel(i int) string {
+	if p.Stages[i].Kind != StageBefore {
+		return string(p.Stages[i].Kind)
+	}
+	before := 0
+	for _, stage := range p.Stages[:i] {
+		if stage.Kind == StageBefore {
+			before++
+		}
+	}
+	return fmt.Sprintf("before #%d", before)
+}
+
+func (p Plan) sourceLabel(in PlanInput) string {
+	switch in.Source {
+	case SourceStage:
+		return fmt.Sprintf("%s (%s)", p.stageLabel(in.Stage), p.Stages[in.Stage].Name)
+	case SourceResponse:
+		return "response of main"
+	}
+	return string(in.Source)
+}
+
+// funcName returns the name of the function, qualified by the name of its
+// package, e.g: main.(*PetStoreHandler).GetPetByName.
+func funcName(val reflect.Value) string {
+	fn := runtime.FuncForPC(val.Pointer())
+	if fn == nil {
+		return val.Type().String()
+	}
+	name := strings.TrimSuffix(fn.Name(), "-fm")
+	return name[strings.LastIndex(name, "/")+1:]
+}
+
+func isEmptyRequestReader(cons RequestReader) bool {
+	return reflect.ValueOf(cons).Pointer() == reflect.ValueOf(emptyRequestReader).Pointer()
+}
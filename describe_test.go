@@ -0,0 +1,100 @@
+package httpwrap
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type describeParams struct {
+	Name string `http:"query=name"`
+}
+
+type describeUser struct{ name string }
+
+func describeAuth(req *http.Request, info ResponseInfo) (describeUser, error) {
+	return describeUser{}, nil
+}
+
+func describeMain(ctx context.Context, db *overrideDB, user describeUser, params describeParams, rw http.ResponseWriter) (string, error) {
+	return "", nil
+}
+
+func TestWrapperDescribe(t *testing.T) {
+	wrapper := NewStandardWrapper().Before(connectDB, describeAuth)
+
+	t.Run("plan", func(t *testing.T) {
+		plan := wrapper.Describe(describeMain)
+		require.Len(t, plan.Stages, 4)
+
+		require.Equal(t, StageBefore, plan.Stages[0].Kind)
+		require.Equal(t, "httpwrap.connectDB", plan.Stages[0].Name)
+		require.Empty(t, plan.Stages[0].Inputs)
+
+		require.Equal(t, []PlanInput{
+			{Type: reflect.TypeOf(&http.Request{}), Source: SourceRequest},
+			{Type: reflect.TypeOf(ResponseInfo{}), Source: SourceResponseWriter},
+		}, plan.Stages[1].Inputs)
+
+		require.Equal(t, StageMain, plan.Stages[2].Kind)
+		require.Equal(t, "httpwrap.describeMain", plan.Stages[2].Name)
+		require.Equal(t, []PlanInput{
+			{Type: reflect.TypeOf((*context.Context)(nil)).Elem(), Source: SourceZero},
+			{Type: reflect.TypeOf(&overrideDB{}), Source: SourceStage, Stage: 0},
+			{Type: reflect.TypeOf(describeUser{}), Source: SourceStage, Stage: 1},
+			{Type: reflect.TypeOf(describeParams{}), Source: SourceConstructor},
+			{Type: reflect.TypeOf((*http.ResponseWriter)(nil)).Elem(), Source: SourceResponseWriter},
+		}, plan.Stages[2].Inputs)
+
+		require.Equal(t, StageFinally, plan.Stages[3].Kind)
+		sources := []InputSource{}
+		for _, in := range plan.Stages[3].Inputs {
+			sources = append(sources, in.Source)
+		}
+		require.Equal(t, []InputSource{SourceResponseWriter, SourceRequest, SourceResponse, SourceError}, sources)
+		require.Equal(t, 2, plan.Stages[3].Inputs[2].Stage)
+	})
+
+	t.Run("overrides and empty reader", func(t *testing.T) {
+		plan := WithOverride(New().Before(connectDB), &overrideDB{name: "fake"}).
+			Describe(func(db *overrideDB, params describeParams) error { return nil })
+		require.Len(t, plan.Stages, 2)
+		require.Equal(t, []PlanInput{
+			{Type: reflect.TypeOf(&overrideDB{}), Source: SourceOverride},
+			{Type: reflect.TypeOf(describeParams{}), Source: SourceZero},
+		}, plan.Stages[1].Inputs)
+	})
+
+	t.Run("text", func(t *testing.T) {
+		text := wrapper.Describe(describeMain).String()
+		require.True(t, strings.HasPrefix(text, "before #0 httpwrap.connectDB\nbefore #1 httpwrap.describeAuth\n"), text)
+		require.Contains(t, text, "\t*httpwrap.overrideDB <- before #0 (httpwrap.connectDB)\n")
+		require.Contains(t, text, "\thttpwrap.describeParams <- constructor\n")
+		require.Contains(t, text, "\tcontext.Context <- zero value\n")
+		require.Contains(t, text, "\tinterface {} <- response of main\n")
+	})
+
+	t.Run("dot", func(t *testing.T) {
+		dot := wrapper.Describe(describeMain).DOT()
+		require.True(t, strings.HasPrefix(dot, "digraph plan {\n"), dot)
+		require.Contains(t, dot, "\tstage2 [label=\"main\\nhttpwrap.describeMain\"];\n")
+		require.Contains(t, dot, "\tstage0 -> stage2 [label=\"*httpwrap.overrideDB\"];\n")
+		require.Contains(t, dot, "\tresponse_writer [label=\"response writer\", shape=ellipse];\n")
+		require.Equal(t, 1, strings.Count(dot, "response_writer [label="))
+		require.Contains(t, dot, "\tconstructor -> stage2 [label=\"httpwrap.describeParams\"];\n")
+		require.True(t, strings.HasSuffix(dot, "}\n"))
+	})
+
+	t.Run("adapter", func(t *testing.T) {
+		plan := wrapper.Describe(Adapter{Handler: describeMain, Call: func(Inputs) ([]any, error) { return nil, nil }})
+		require.Equal(t, "httpwrap.describeMain", plan.Stages[2].Name)
+	})
+
+	t.Run("invalid main", func(t *testing.T) {
+		require.Panics(t, func() { wrapper.Describe(func(any) {}) })
+	})
+}
@@ -0,0 +1,278 @@
+package httpwrap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/apourchet/httpwrap/defaults"
+)
+
+// Encoder is the inverse of the Decoder: it builds http requests out of
+// the same tagged structs that the Decoder reads requests into.
+type Encoder struct {
+	// CookieKeys holds the keys used to sign and encrypt the cookies
+	// tagged as signed or encrypted.
+	CookieKeys CookieKeys
+}
+
+// NewEncoder returns a new encoder.
+func NewEncoder() *Encoder {
+	return &Encoder{}
+}
+
+// WithCookieKeys sets the keys used to sign and encrypt cookies, and
+// returns the encoder.
+func (e *Encoder) WithCookieKeys(keys CookieKeys) *Encoder {
+	e.CookieKeys = keys
+	return e
+}
+
+// Encode builds a request for the method and path template out of the
+// parameter object. Given a struct definition:
+//
+//	type Request struct {
+//			Resource string      `http:"segment=resource"`
+//			Limit int            `http:"query=limit"`
+//			AuthString string    `http:"header=Authorization"`
+//			Session string       `http:"cookie=session,signed"`
+//			Token string         `http:"bearer"`
+//			Extra map[string]int `json:"extra"`
+//	}
+//
+// The Resource field replaces the {resource} wildcard of the path template
+// (e.g: https://pets.example.com/api/pets/{resource}), which must be present.
+//
+// The Limit field is set in the query string, the AuthString field as a
+// header and the Session field as a cookie, signed with the CookieKeys of the
+// encoder. Zero values are left out of the request.
+//
+// The User and Password fields tagged with `basicauth` are sent as Basic
+// credentials, and the Token field as a Bearer token.
+//
+// The Extra field is sent in the JSON body of the request, unless the
+// method is GET or HEAD.
+func (e *Encoder) Encode(ctx context.Context, method, pathTemplate string, in any) (*http.Request, error) {
+	v, valid := defaults.DerefValue(in)
+	req := &http.Request{Header: http.Header{}}
+	query := url.Values{}
+	segments := map[string]string{}
+	omitted := map[string]bool{}
+	var user, password *string
+	hasBody := valid && v.Kind() != reflect.Struct
+
+	if valid && v.Kind() == reflect.Struct {
+		hasBody = len(bodyFields(v.Type())) > 0
+
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			directive, found := field.Tag.Lookup("http")
+			if !found || directive == "" || !field.IsExported() {
+				continue
+			}
+			omitted[jsonFieldName(field)] = true
+
+			tagkey, tagval, _ := strings.Cut(directive, "=")
+			name, option, _ := strings.Cut(tagval, ",")
+			f := v.Field(i)
+			if tagkey == "segment" {
+				vals, err := encodeStrings(f)
+				if err != nil {
+					return nil, &EncodeError{Source: tagkey, Key: name, Err: err}
+				}
+				segments[name] = strings.Join(vals, "")
+				continue
+			} else if f.IsZero() {
+				continue
+			}
+
+			vals, err := encodeStrings(f)
+			if err != nil {
+				return nil, &EncodeError{Source: tagkey, Key: name, Err: err}
+			}
+
+			switch tagkey {
+			case "query":
+				query[name] = append(query[name], vals...)
+			case "header":
+				for _, val := range vals {
+					req.Header.Add(name, val)
+				}
+			case "cookie":
+				if err := e.addCookie(req, name, option, vals[0]); err != nil {
+					return nil, &EncodeError{Source: tagkey, Key: name, Err: err}
+				}
+			case "basicauth":
+				if name == "user" {
+					user = &vals[0]
+				} else if name == "password" {
+					password = &vals[0]
+				} else {
+					return nil, fmt.Errorf("unrecognized basicauth value %v", name)
+				}
+			case "bearer":
+				req.Header.Set("Authorization", "Bearer "+vals[0])
+			default:
+				return nil, fmt.Errorf("unrecognized http tag %v", tagkey)
+			}
+		}
+	}
+
+	target, err := expandPath(pathTemplate, segments)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+	if len(query) > 0 {
+		for key, vals := range parsed.Query() {
+			query[key] = append(vals, query[key]...)
+		}
+		parsed.RawQuery = query.Encode()
+	}
+
+	var body io.Reader
+	hasBody = hasBody && method != http.MethodGet && method != http.MethodHead
+	if hasBody {
+		encoded, err := (responseMeta{omitted: omitted}).body(in)
+		if err != nil {
+			return nil, &EncodeError{Source: "body", Err: err}
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	out, err := http.NewRequestWithContext(ctx, method, parsed.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for key, vals := range req.Header {
+		out.Header[key] = vals
+	}
+	if hasBody {
+		out.Header.Set("Content-Type", "application/json")
+	}
+	if user != nil || password != nil {
+		out.SetBasicAuth(deref(user), deref(password))
+	}
+	return out, nil
+}
+
+// EncodeError is the error returned by the Encoder when a field of the
+// parameter object cannot be encoded into the request.
+type EncodeError struct {
+	Source string
+	Key    string
+	Err    error
+}
+
+func (err *EncodeError) Error() string {
+	if err.Key == "" {
+		return fmt.Sprintf("failed to encode request %s: %v", err.Source, err.Err)
+	}
+	return fmt.Sprintf("failed to encode request %s %q: %v", err.Source, err.Key, err.Err)
+}
+
+func (err *EncodeError) Unwrap() error { return err.Err }
+
+func (e *Encoder) addCookie(req *http.Request, name, option, val string) (err error) {
+	switch option {
+	case "":
+	case "signed":
+		val, err = e.CookieKeys.Sign(name, val)
+	case "encrypted":
+		val, err = e.CookieKeys.Encrypt(name, val)
+	default:
+		return fmt.Errorf("unrecognized cookie option %v", option)
+	}
+	if err != nil {
+		return err
+	}
+	req.AddCookie(&http.Cookie{Name: name, Value: url.PathEscape(val)})
+	return nil
+}
+
+// encodeStrings returns the string values of the field, as read back by
+// defaults.GenVal. Slices produce one value per element.
+func encodeStrings(field reflect.Value) ([]string, error) {
+	for field.Kind() == reflect.Ptr || field.Kind() == reflect.Interface {
+		if field.IsNil() {
+			return []string{""}, nil
+		}
+		field = field.Elem()
+	}
+
+	if field.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.Uint8 {
+		vals := make([]string, 0, field.Len())
+		for i := 0; i < field.Len(); i++ {
+			val, err := encodeString(field.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			vals = append(vals, val)
+		}
+		return vals, nil
+	}
+
+	val, err := encodeString(field)
+	return []string{val}, err
+}
+
+// encodeString returns the string value of a single value. Strings are
+// kept as they are and other values are JSON encoded, without the quotes
+// of JSON strings.
+func encodeString(val reflect.Value) (string, error) {
+	if val.Kind() == reflect.String {
+		return val.String(), nil
+	}
+
+	encoded, err := json.Marshal(val.Interface())
+	if err != nil {
+		return "", err
+	}
+	var str string
+	if err := json.Unmarshal(encoded, &str); err == nil {
+		return str, nil
+	}
+	return string(encoded), nil
+}
+
+// expandPath replaces the wildcards of the path template with the escaped
+// segments. Wildcards ending with ... can hold several path segments.
+func expandPath(pathTemplate string, segments map[string]string) (string, error) {
+	var err error
+	expanded := _pathSegment.ReplaceAllStringFunc(pathTemplate, func(wildcard string) string {
+		match := _pathSegment.FindStringSubmatch(wildcard)
+		val, found := segments[match[1]]
+		if match[1] == "$" {
+			return ""
+		} else if !found {
+			err = fmt.Errorf("no value for the segment %q of the path %s", match[1], pathTemplate)
+			return wildcard
+		} else if match[2] == "" {
+			return url.PathEscape(val)
+		}
+
+		parts := strings.Split(val, "/")
+		for i, part := range parts {
+			parts[i] = url.PathEscape(part)
+		}
+		return strings.Join(parts, "/")
+	})
+	return expanded, err
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
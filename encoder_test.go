@@ -0,0 +1,89 @@
+package httpwrap
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type encodedParams struct {
+	Resource string   `http:"segment=resource"`
+	Path     string   `http:"segment=path"`
+	Limit    int      `http:"query=limit"`
+	Tags     []string `http:"query=tag"`
+	Trace    string   `http:"header=X-Trace"`
+	Session  string   `http:"cookie=session,signed"`
+	Secret   string   `http:"cookie=secret,encrypted"`
+	User     string   `http:"basicauth=user"`
+	Password string   `http:"basicauth=password"`
+	Extra    map[string]int
+	Skipped  string `json:"-"`
+}
+
+func TestEncoder(t *testing.T) {
+	keys := CookieKeys{
+		Signing:    [][]byte{[]byte("signing-key")},
+		Encryption: [][]byte{[]byte("0123456789abcdef")},
+	}
+	in := encodedParams{
+		Resource: "pets & co",
+		Path:     "a/b c",
+		Limit:    10,
+		Tags:     []string{"cute", "small"},
+		Trace:    "abc",
+		Session:  "user-1",
+		Secret:   "hidden",
+		User:     "admin",
+		Password: "hunter2",
+		Extra:    map[string]int{"age": 3},
+		Skipped:  "skipped",
+	}
+
+	req, err := NewEncoder().WithCookieKeys(keys).Encode(context.Background(), "POST",
+		"https://pets.example.com/api/{resource}/files/{path...}?page=2", in)
+	require.NoError(t, err)
+	require.Equal(t, "https://pets.example.com/api/pets%20&%20co/files/a/b%20c?limit=10&page=2&tag=cute&tag=small", req.URL.String())
+	require.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"Extra":{"age":3}}`, string(body))
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	// The decoder reads back the same values.
+	router := http.NewServeMux()
+	var out encodedParams
+	router.HandleFunc("POST /api/{resource}/files/{path...}", func(rw http.ResponseWriter, req *http.Request) {
+		out = encodedParams{}
+		require.NoError(t, NewDecoder().WithCookieKeys(keys).Decode(req, &out))
+	})
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	in.Skipped = ""
+	require.Equal(t, in, out)
+
+	t.Run("no body for GET", func(t *testing.T) {
+		req, err := NewEncoder().Encode(context.Background(), "GET", "/api/{resource}/files/{path}", encodedParams{Resource: "pets"})
+		require.NoError(t, err)
+		require.Equal(t, "/api/pets/files/", req.URL.String())
+		require.Nil(t, req.Body)
+		require.Empty(t, req.Header)
+	})
+
+	t.Run("missing segment", func(t *testing.T) {
+		_, err := NewEncoder().Encode(context.Background(), "GET", "/api/{id}", encodedParams{})
+		require.Error(t, err)
+	})
+
+	t.Run("cookie keys", func(t *testing.T) {
+		_, err := NewEncoder().Encode(context.Background(), "GET", "/api/{resource}/{path}", encodedParams{Session: "user-1"})
+		var encodeErr *EncodeError
+		require.True(t, errors.As(err, &encodeErr))
+		require.Equal(t, "cookie", encodeErr.Source)
+		require.Equal(t, "session", encodeErr.Key)
+	})
+}
@@ -1,8 +1,10 @@
 package httpwrap
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 )
 
 type HTTPError interface {
@@ -13,8 +15,9 @@ type HTTPError interface {
 // httpError implements both the HTTPResponse interface and the standard error
 // interface.
 type httpError struct {
-	code int
-	body string
+	code    int
+	body    string
+	headers http.Header
 }
 
 func NewHTTPError(code int, format string, args ...any) HTTPError {
@@ -30,6 +33,8 @@ func (err httpError) Error() string {
 
 func (err httpError) StatusCode() int { return err.code }
 
+func (err httpError) Headers() http.Header { return err.headers }
+
 func (err httpError) WriteBody(writer io.Writer) error {
 	_, writeError := io.WriteString(writer, err.body)
 	return writeError
@@ -39,3 +44,68 @@ func (err httpError) WriteBody(writer io.Writer) error {
 // deserialization logic. This can be used when the endpoint or middleware
 // operates directly on the native http.ResponseWriter.
 func NewNoopError() HTTPError { return NewHTTPError(0, "") }
+
+// ErrorRegistry maps errors that do not carry any HTTP information, such as
+// domain sentinel errors, to the HTTP responses sent to the client. This lets
+// the business logic stay free of HTTP types. Errors are matched in the order
+// in which they were registered, unwrapping them as errors.Is and errors.As
+// do.
+type ErrorRegistry struct {
+	entries []errorEntry
+}
+
+type errorEntry struct {
+	match   func(error) bool
+	respond func(error) HTTPResponse
+}
+
+// NewErrorRegistry returns an empty ErrorRegistry.
+func NewErrorRegistry() *ErrorRegistry {
+	return &ErrorRegistry{}
+}
+
+// Register maps the errors that match the target with errors.Is to the
+// status code. The body of the response is the message of the target, so
+// that the context added when wrapping the error is not sent to the client.
+func (registry *ErrorRegistry) Register(target error, code int) *ErrorRegistry {
+	return registry.RegisterFunc(target, func(error) HTTPResponse {
+		return NewHTTPError(code, "%s", target.Error())
+	})
+}
+
+// RegisterFunc maps the errors that match the target with errors.Is to the
+// response built by the function.
+func (registry *ErrorRegistry) RegisterFunc(target error, fn func(error) HTTPResponse) *ErrorRegistry {
+	registry.entries = append(registry.entries, errorEntry{
+		match:   func(err error) bool { return errors.Is(err, target) },
+		respond: fn,
+	})
+	return registry
+}
+
+// RegisterErrorType maps the errors that match the type T with errors.As to
+// the response built by the function.
+func RegisterErrorType[T error](registry *ErrorRegistry, fn func(T) HTTPResponse) *ErrorRegistry {
+	registry.entries = append(registry.entries, errorEntry{
+		match: func(err error) bool {
+			var target T
+			return errors.As(err, &target)
+		},
+		respond: func(err error) HTTPResponse {
+			var target T
+			errors.As(err, &target)
+			return fn(target)
+		},
+	})
+	return registry
+}
+
+// Lookup returns the response that the error maps to.
+func (registry *ErrorRegistry) Lookup(err error) (HTTPResponse, bool) {
+	for _, entry := range registry.entries {
+		if entry.match(err) {
+			return entry.respond(err), true
+		}
+	}
+	return nil, false
+}
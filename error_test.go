@@ -0,0 +1,66 @@
+package httpwrap
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var errPetNotFound = errors.New("pet not found")
+
+type quotaError struct{ limit int }
+
+func (err *quotaError) Error() string { return fmt.Sprintf("quota of %d exceeded", err.limit) }
+
+func TestErrorRegistry(t *testing.T) {
+	registry := NewErrorRegistry().Register(errPetNotFound, http.StatusNotFound)
+	RegisterErrorType(registry, func(err *quotaError) HTTPResponse {
+		return NewJSONResponse(http.StatusTooManyRequests, map[string]int{"limit": err.limit})
+	})
+
+	t.Run("lookup", func(t *testing.T) {
+		res, found := registry.Lookup(fmt.Errorf("loading pet: %w", errPetNotFound))
+		require.True(t, found)
+		require.Equal(t, http.StatusNotFound, res.StatusCode())
+
+		res, found = registry.Lookup(fmt.Errorf("listing: %w", &quotaError{limit: 10}))
+		require.True(t, found)
+		require.Equal(t, http.StatusTooManyRequests, res.StatusCode())
+
+		_, found = registry.Lookup(errors.New("other"))
+		require.False(t, found)
+	})
+
+	t.Run("writer", func(t *testing.T) {
+		wrapper := NewStandardWrapper().Finally(StandardResponseWriter(WithErrors(registry)))
+
+		rw := httptest.NewRecorder()
+		handler := wrapper.Wrap(func() error { return fmt.Errorf("loading pet: %w", errPetNotFound) })
+		handler.ServeHTTP(rw, httptest.NewRequest("GET", "/pets/rex", nil))
+		statusCode, body := readResponseRecorder(t, rw)
+		require.Equal(t, http.StatusNotFound, statusCode)
+		require.Equal(t, "pet not found", body)
+
+		rw = httptest.NewRecorder()
+		handler = wrapper.Wrap(func() error { return &quotaError{limit: 10} })
+		handler.ServeHTTP(rw, httptest.NewRequest("GET", "/pets", nil))
+		statusCode, body = readResponseRecorder(t, rw)
+		require.Equal(t, http.StatusTooManyRequests, statusCode)
+		require.JSONEq(t, `{"limit":10}`, body)
+	})
+
+	t.Run("writer with problem details", func(t *testing.T) {
+		wrapper := NewStandardWrapper().Finally(StandardResponseWriter(WithErrors(registry), WithProblemDetails()))
+
+		rw := httptest.NewRecorder()
+		handler := wrapper.Wrap(func() error { return fmt.Errorf("loading pet: %w", errPetNotFound) })
+		handler.ServeHTTP(rw, httptest.NewRequest("GET", "/pets/rex", nil))
+		statusCode, body := readResponseRecorder(t, rw)
+		require.Equal(t, http.StatusNotFound, statusCode)
+		require.Contains(t, body, `"code":"not_found"`)
+	})
+}
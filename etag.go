@@ -0,0 +1,144 @@
+package httpwrap
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// ETagger can be implemented by response objects that know their own
+// version, to be used as their ETag instead of the hash of their encoded
+// body. The value is quoted if it is not already a valid entity tag.
+type ETagger interface {
+	ETag() string
+}
+
+// setETag sets the ETag header of the response if it is not already set,
+// and returns whether the If-None-Match header of a GET or HEAD request
+// matches it.
+func setETag(w http.ResponseWriter, req *http.Request, res any, body []byte) bool {
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		if tagger, ok := res.(ETagger); ok {
+			etag = formatETag(tagger.ETag())
+		} else {
+			sum := sha256.Sum256(body)
+			etag = `"` + hex.EncodeToString(sum[:16]) + `"`
+		}
+		if etag == "" {
+			return false
+		}
+		w.Header().Set("ETag", etag)
+	}
+
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+	return matchETags(req.Header.Get("If-None-Match"), etag, true)
+}
+
+// formatETag quotes the value if it is not already an entity tag.
+func formatETag(value string) string {
+	if value == "" || strings.HasPrefix(value, `"`) || strings.HasPrefix(value, `W/"`) {
+		return value
+	}
+	return `"` + value + `"`
+}
+
+// matchETags returns whether one of the entity tags of the If-Match or
+// If-None-Match header matches the ETag. If-None-Match uses the weak
+// comparison, which ignores the W/ prefix, and If-Match the strong one.
+func matchETags(header, etag string, weak bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return etag != ""
+	}
+	if !weak && strings.HasPrefix(etag, "W/") {
+		return false
+	}
+	for _, candidate := range parseETags(header) {
+		if !weak && strings.HasPrefix(candidate, "W/") {
+			continue
+		}
+		if strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseETags returns the entity tags of an If-Match or If-None-Match header.
+func parseETags(header string) []string {
+	etags := []string{}
+	for {
+		header = strings.TrimLeft(header, " \t,")
+		if header == "" {
+			return etags
+		}
+
+		start := 0
+		if strings.HasPrefix(header, "W/") {
+			start = 2
+		}
+		if len(header) <= start || header[start] != '"' {
+			return etags
+		}
+		end := strings.IndexByte(header[start+1:], '"')
+		if end < 0 {
+			return etags
+		}
+		end += start + 2
+		etags = append(etags, header[:end])
+		header = header[end:]
+	}
+}
+
+// IfMatch is the If-Match precondition of a request, used by handlers to
+// implement optimistic concurrency. It is provided by the ReadIfMatch
+// before:
+//
+//	wrapper := httpwrap.NewStandardWrapper().Before(httpwrap.ReadIfMatch)
+//
+//	func UpdatePet(ifMatch httpwrap.IfMatch, params UpdateParams) error {
+//		pet := loadPet(params.Name)
+//		if err := ifMatch.Check(pet.ETag()); err != nil {
+//			return err
+//		}
+//		...
+//	}
+type IfMatch struct {
+	header string
+}
+
+// ReadIfMatch reads the If-Match precondition of requests with unsafe
+// methods. The precondition of GET, HEAD, OPTIONS and TRACE requests is
+// always empty.
+func ReadIfMatch(req *http.Request) IfMatch {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return IfMatch{}
+	}
+	return IfMatch{header: req.Header.Get("If-Match")}
+}
+
+// Present returns whether the request carried an If-Match precondition.
+func (m IfMatch) Present() bool { return m.header != "" }
+
+// Matches returns whether the current ETag of the resource satisfies the
+// precondition, using the strong comparison. An absent precondition is
+// always satisfied. The ETag is quoted if it is not already an entity tag.
+func (m IfMatch) Matches(etag string) bool {
+	if !m.Present() {
+		return true
+	}
+	return matchETags(m.header, formatETag(etag), false)
+}
+
+// Check returns a 412 Precondition Failed HTTPError if the current ETag of
+// the resource does not satisfy the precondition.
+func (m IfMatch) Check(etag string) error {
+	if m.Matches(etag) {
+		return nil
+	}
+	return NewHTTPError(http.StatusPreconditionFailed, "Precondition Failed.")
+}
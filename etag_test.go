@@ -0,0 +1,102 @@
+package httpwrap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type versionedResponse struct {
+	Version int `json:"version"`
+}
+
+func (res versionedResponse) ETag() string { return "v1" }
+
+func TestETags(t *testing.T) {
+	wrapper := NewStandardWrapper().Finally(StandardResponseWriter(WithETags()))
+
+	t.Run("hashed body", func(t *testing.T) {
+		handler := wrapper.Wrap(func() typedResponse { return typedResponse{Value: 42} })
+
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, httptest.NewRequest("GET", "/endpoint", nil))
+		statusCode, body := readResponseRecorder(t, rw)
+		require.Equal(t, http.StatusOK, statusCode)
+		require.Equal(t, `{"value":42}`, body)
+		etag := rw.Result().Header.Get("ETag")
+		require.Regexp(t, `^"[0-9a-f]{32}"$`, etag)
+
+		rw = httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/endpoint", nil)
+		req.Header.Set("If-None-Match", `"other", W/`+etag)
+		handler.ServeHTTP(rw, req)
+		statusCode, body = readResponseRecorder(t, rw)
+		require.Equal(t, http.StatusNotModified, statusCode)
+		require.Empty(t, body)
+		require.Equal(t, etag, rw.Result().Header.Get("ETag"))
+	})
+
+	t.Run("explicit etag", func(t *testing.T) {
+		handler := wrapper.Wrap(func() versionedResponse { return versionedResponse{Version: 1} })
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/endpoint", nil)
+		req.Header.Set("If-None-Match", `"v0"`)
+		handler.ServeHTTP(rw, req)
+		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+		require.Equal(t, `"v1"`, rw.Result().Header.Get("ETag"))
+
+		rw = httptest.NewRecorder()
+		req.Header.Set("If-None-Match", `"v1"`)
+		handler.ServeHTTP(rw, req)
+		require.Equal(t, http.StatusNotModified, rw.Result().StatusCode)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		handler := NewStandardWrapper().Wrap(func() typedResponse { return typedResponse{Value: 42} })
+
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, httptest.NewRequest("GET", "/endpoint", nil))
+		require.Empty(t, rw.Result().Header.Get("ETag"))
+	})
+}
+
+func TestIfMatch(t *testing.T) {
+	handler := NewStandardWrapper().
+		Before(ReadIfMatch).
+		Wrap(func(ifMatch IfMatch) error {
+			return ifMatch.Check("v2")
+		})
+
+	t.Run("no precondition", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, httptest.NewRequest("PUT", "/pets/rex", nil))
+		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+	})
+
+	t.Run("matching precondition", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("PUT", "/pets/rex", nil)
+		req.Header.Set("If-Match", `"v1", "v2"`)
+		handler.ServeHTTP(rw, req)
+		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+	})
+
+	t.Run("failed precondition", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("PUT", "/pets/rex", nil)
+		req.Header.Set("If-Match", `W/"v2"`)
+		handler.ServeHTTP(rw, req)
+		require.Equal(t, http.StatusPreconditionFailed, rw.Result().StatusCode)
+	})
+
+	t.Run("ignored on safe methods", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/pets/rex", nil)
+		req.Header.Set("If-Match", `"v1"`)
+		handler.ServeHTTP(rw, req)
+		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+	})
+}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
@@ -48,33 +47,6 @@ func (mw *Middlewares) checkAPICreds(creds APICredentials) error {
 	return ErrBadAPICreds
 }
 
-// sendResponse writes out the response to the client given the output
-// of the handler.
-func (mw *Middlewares) sendResponse(w http.ResponseWriter, res any, err error) {
-	switch err {
-	case ErrBadAPICreds:
-		w.WriteHeader(http.StatusUnauthorized)
-	case ErrPetConflict:
-		w.WriteHeader(http.StatusConflict)
-	case ErrPetNotFound:
-		w.WriteHeader(http.StatusNotFound)
-	default:
-		w.WriteHeader(http.StatusInternalServerError)
-	}
-
-	if err != nil {
-		if _, err := w.Write([]byte(err.Error() + "\n")); err != nil {
-			log.Println("error writing response:", err)
-		}
-	} else {
-		encoder := json.NewEncoder(w)
-		encoder.SetIndent("", "  ")
-		if err := encoder.Encode(res); err != nil {
-			log.Println("Error writing response:", err)
-		}
-	}
-}
-
 // ***** Handler Methods *****
 // AddPet adds a new pet to the store.
 func (h *PetStoreHandler) AddPet(pet Pet) error {
@@ -158,10 +130,17 @@ func main() {
 	handler := &PetStoreHandler{pets: map[string]*Pet{}}
 	mw := &Middlewares{}
 
+	// The domain errors are mapped to their status codes by the response
+	// writer, the handlers do not need to know about HTTP.
+	errs := httpwrap.NewErrorRegistry().
+		Register(ErrBadAPICreds, http.StatusUnauthorized).
+		Register(ErrPetConflict, http.StatusConflict).
+		Register(ErrPetNotFound, http.StatusNotFound)
+
 	wrapper := httpwrap.New().
 		WithRequestReader(httpwrap.StandardRequestReader()).
 		Before(mw.checkAPICreds).
-		Finally(mw.sendResponse)
+		Finally(httpwrap.StandardResponseWriter(httpwrap.WithErrors(errs)))
 
 	router := http.NewServeMux()
 	router.Handle("POST /pets", wrapper.Wrap(handler.AddPet))
@@ -0,0 +1,79 @@
+// Code generated by httpwrap-gen; DO NOT EDIT.
+
+package main
+
+import (
+	"net/http"
+
+	"github.com/apourchet/httpwrap"
+	"github.com/apourchet/httpwrap/defaults"
+)
+
+// PetStoreHandlerGetPetByNameAdapter returns the Adapter of (*PetStoreHandler).GetPetByName,
+// which decodes the parameters of the handler with the Decoder of the wrapper.
+func PetStoreHandlerGetPetByNameAdapter(recv *PetStoreHandler) httpwrap.Adapter {
+	return httpwrap.Adapter{
+		Handler: recv.GetPetByName,
+		Call: func(in httpwrap.Inputs) ([]any, error) {
+			p0, err := httpwrap.DecodeInput(in, decodeGetByNameParams)
+			if err != nil {
+				return nil, err
+			}
+			o0, o1 := recv.GetPetByName(p0)
+			return []any{o0, o1}, nil
+		},
+	}
+}
+
+// PetStoreHandlerFilterPetsAdapter returns the Adapter of (*PetStoreHandler).FilterPets,
+// which decodes the parameters of the handler with the Decoder of the wrapper.
+func PetStoreHandlerFilterPetsAdapter(recv *PetStoreHandler) httpwrap.Adapter {
+	return httpwrap.Adapter{
+		Handler: recv.FilterPets,
+		Call: func(in httpwrap.Inputs) ([]any, error) {
+			p0, err := httpwrap.DecodeInput(in, decodeFilterPetParams)
+			if err != nil {
+				return nil, err
+			}
+			o0 := recv.FilterPets(p0)
+			return []any{o0}, nil
+		},
+	}
+}
+
+// decodeGetByNameParams decodes the request into a GetByNameParams, like the Decoder does.
+func decodeGetByNameParams(d *httpwrap.Decoder, req *http.Request, obj *GetByNameParams) error {
+	if err := d.DecodeBody(req, obj); err != nil {
+		return &httpwrap.DecodeError{Source: "body", Err: err}
+	}
+	if vals, err := d.Values(req, "segment", "name"); err != nil {
+		return err
+	} else if len(vals) > 0 {
+		if err := defaults.Gen(&obj.Name, vals[0], vals[1:]...); err != nil {
+			return &httpwrap.DecodeError{Source: "segment", Key: "name", Err: err}
+		}
+	}
+	return nil
+}
+
+// decodeFilterPetParams decodes the request into a FilterPetParams, like the Decoder does.
+func decodeFilterPetParams(d *httpwrap.Decoder, req *http.Request, obj *FilterPetParams) error {
+	if err := d.DecodeBody(req, obj); err != nil {
+		return &httpwrap.DecodeError{Source: "body", Err: err}
+	}
+	if vals, err := d.Values(req, "query", "categories"); err != nil {
+		return err
+	} else if len(vals) > 0 {
+		if err := defaults.Gen(&obj.Categories, vals[0], vals[1:]...); err != nil {
+			return &httpwrap.DecodeError{Source: "query", Key: "categories", Err: err}
+		}
+	}
+	if vals, err := d.Values(req, "query", "hasPhotos"); err != nil {
+		return err
+	} else if len(vals) > 0 {
+		if err := defaults.Gen(&obj.HasPhotos, vals[0], vals[1:]...); err != nil {
+			return &httpwrap.DecodeError{Source: "query", Key: "hasPhotos", Err: err}
+		}
+	}
+	return nil
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/apourchet/httpwrap"
 )
 
+//go:generate go run github.com/apourchet/httpwrap/cmd/httpwrap-gen -handlers PetStoreHandler.GetPetByName,PetStoreHandler.FilterPets
+
 // ***** Type Definitions *****
 type APICredentials struct {
 	Key string `http:"header=X-PETSTORE-KEY"`
@@ -125,17 +127,19 @@ func (h *PetStoreHandler) ClearStore() error {
 
 func main() {
 	handler := &PetStoreHandler{pets: map[string]*Pet{}}
-	wrapper := httpwrap.NewStandardWrapper().Before(checkAPICreds)
-
-	router := http.NewServeMux()
-	router.Handle("POST /pets", wrapper.Wrap(handler.AddPet))
-	router.Handle("GET /pets", wrapper.Wrap(handler.GetPets))
-	router.Handle("GET /pets/filtered", wrapper.Wrap(handler.FilterPets))
-	router.Handle("GET /pets/{name}", wrapper.Wrap(handler.GetPetByName))
-	router.Handle("PUT /pets/{name}", wrapper.Wrap(handler.UpdatePet))
-
-	router.Handle("POST /clear", wrapper.Wrap(handler.ClearStore))
+	router := httpwrap.NewRouter(httpwrap.NewStandardWrapper())
+	router.Use(checkAPICreds)
+	router.Group("/pets", func(g *httpwrap.Router) {
+		g.POST("", handler.AddPet)
+		g.GET("", handler.GetPets)
+		// The hot routes use the adapters generated by httpwrap-gen, which
+		// do not go through reflection.
+		g.GET("/filtered", PetStoreHandlerFilterPetsAdapter(handler))
+		g.GET("/{name}", PetStoreHandlerGetPetByNameAdapter(handler))
+		g.PUT("/{name}", handler.UpdatePet)
+	})
+
+	router.POST("/clear", handler.ClearStore)
 
-	http.Handle("/", router)
 	log.Fatal(http.ListenAndServe(":3000", router))
 }
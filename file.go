@@ -0,0 +1,130 @@
+package httpwrap
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"reflect"
+	"time"
+)
+
+// _sniffLen is the number of bytes used to detect the content type of files,
+// which is all that http.DetectContentType considers.
+const _sniffLen = 512
+
+// FileResponse is a response whose body is read from Content. When Content
+// is an io.ReadSeeker, the response is sent with http.ServeContent, which
+// handles Range, If-Modified-Since, If-None-Match and HEAD requests. Content
+// is closed once sent if it implements io.Closer.
+type FileResponse struct {
+	// Content is the body of the response.
+	Content io.Reader
+
+	// Name is the name of the file. When set, the response is sent as an
+	// attachment with this filename, and its extension is used to detect the
+	// content type if ContentType is empty.
+	Name string
+
+	// ContentType is the Content-Type of the response. When empty, it is
+	// detected from the extension of the file name, or else from the first
+	// bytes of its content.
+	ContentType string
+
+	// ModTime is the modification time of the file, used for conditional
+	// requests. When zero, it is read from the Stat method of Content if it
+	// has one.
+	ModTime time.Time
+
+	// Inline sends the file with an inline Content-Disposition instead of
+	// an attachment.
+	Inline bool
+}
+
+// fileResponse returns the FileResponse for response objects that are files
+// or readers. FileResponses without Content are returned as well, for
+// writeFile to reject them.
+func fileResponse(res any) (FileResponse, bool) {
+	if v := reflect.ValueOf(res); v.Kind() == reflect.Ptr && v.IsNil() {
+		return FileResponse{}, false
+	}
+
+	switch cast := res.(type) {
+	case FileResponse:
+		return cast, true
+	case *FileResponse:
+		return *cast, true
+	case io.Reader:
+		return FileResponse{Content: cast}, true
+	}
+	return FileResponse{}, false
+}
+
+// writeFile sends the content of the file to the client.
+func (config *responseConfig) writeFile(w http.ResponseWriter, req *http.Request, file FileResponse) {
+	if file.Content == nil {
+		config.logger.ErrorContext(req.Context(), "error writing response", "error", errors.New("file response has no content"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if closer, ok := file.Content.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	// The name used to detect the content type is the one of the file on
+	// disk if it was not given explicitly.
+	name, modTime := file.Name, file.ModTime
+	if stat, ok := file.Content.(interface{ Stat() (fs.FileInfo, error) }); ok {
+		if info, err := stat.Stat(); err == nil {
+			if name == "" {
+				name = info.Name()
+			}
+			if modTime.IsZero() {
+				modTime = info.ModTime()
+			}
+		}
+	}
+
+	if file.ContentType != "" {
+		w.Header().Set("Content-Type", file.ContentType)
+	}
+	if file.Name != "" {
+		disposition := "attachment"
+		if file.Inline {
+			disposition = "inline"
+		}
+		w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": file.Name}))
+	}
+
+	if seeker, ok := file.Content.(io.ReadSeeker); ok {
+		http.ServeContent(w, req, name, modTime, seeker)
+		return
+	}
+
+	content := file.Content
+	if w.Header().Get("Content-Type") == "" {
+		contentType := mime.TypeByExtension(path.Ext(name))
+		if contentType == "" {
+			// Like http.ServeContent, sniff the first bytes of the content.
+			// Read errors are kept by the buffered reader and logged when
+			// the content is copied.
+			buffered := bufio.NewReaderSize(content, _sniffLen)
+			sniffed, _ := buffered.Peek(_sniffLen)
+			contentType, content = http.DetectContentType(sniffed), buffered
+		}
+		w.Header().Set("Content-Type", contentType)
+	}
+	if !modTime.IsZero() {
+		w.Header().Set("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+	}
+	w.WriteHeader(http.StatusOK)
+	if req.Method == http.MethodHead {
+		return
+	}
+	if _, err := io.Copy(w, content); err != nil {
+		config.logger.ErrorContext(req.Context(), "error writing response", "error", err)
+	}
+}
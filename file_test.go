@@ -0,0 +1,106 @@
+package httpwrap
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileResponses(t *testing.T) {
+	wrapper := NewStandardWrapper()
+
+	t.Run("os file with range", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "artifact.txt")
+		require.NoError(t, os.WriteFile(path, []byte("0123456789"), 0o600))
+
+		handler := wrapper.Wrap(func() (*os.File, error) {
+			return os.Open(path)
+		})
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/artifact", nil)
+		req.Header.Set("Range", "bytes=2-5")
+		handler.ServeHTTP(rw, req)
+		statusCode, body := readResponseRecorder(t, rw)
+		require.Equal(t, http.StatusPartialContent, statusCode)
+		require.Equal(t, "2345", body)
+		require.Equal(t, "text/plain; charset=utf-8", rw.Result().Header.Get("Content-Type"))
+		require.NotEmpty(t, rw.Result().Header.Get("Last-Modified"))
+	})
+
+	t.Run("file response", func(t *testing.T) {
+		modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		handler := wrapper.Wrap(func() FileResponse {
+			return FileResponse{
+				Content:     bytes.NewReader([]byte("a,b\n1,2\n")),
+				Name:        "export.csv",
+				ContentType: "text/csv",
+				ModTime:     modTime,
+			}
+		})
+
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, httptest.NewRequest("GET", "/export", nil))
+		statusCode, body := readResponseRecorder(t, rw)
+		require.Equal(t, http.StatusOK, statusCode)
+		require.Equal(t, "a,b\n1,2", body)
+		require.Equal(t, "text/csv", rw.Result().Header.Get("Content-Type"))
+		require.Equal(t, `attachment; filename=export.csv`, rw.Result().Header.Get("Content-Disposition"))
+
+		rw = httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/export", nil)
+		req.Header.Set("If-Modified-Since", modTime.Format(http.TimeFormat))
+		handler.ServeHTTP(rw, req)
+		require.Equal(t, http.StatusNotModified, rw.Result().StatusCode)
+
+		rw = httptest.NewRecorder()
+		handler.ServeHTTP(rw, httptest.NewRequest("HEAD", "/export", nil))
+		statusCode, body = readResponseRecorder(t, rw)
+		require.Equal(t, http.StatusOK, statusCode)
+		require.Empty(t, body)
+	})
+
+	t.Run("plain reader", func(t *testing.T) {
+		handler := wrapper.Wrap(func() io.Reader {
+			return io.MultiReader(strings.NewReader("hello "), strings.NewReader("world"))
+		})
+
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, httptest.NewRequest("GET", "/raw", nil))
+		statusCode, body := readResponseRecorder(t, rw)
+		require.Equal(t, http.StatusOK, statusCode)
+		require.Equal(t, "hello world", body)
+		require.Equal(t, "text/plain; charset=utf-8", rw.Result().Header.Get("Content-Type"))
+	})
+
+	t.Run("binary reader", func(t *testing.T) {
+		handler := wrapper.Wrap(func() FileResponse {
+			return FileResponse{Name: "dump", Content: io.MultiReader(bytes.NewReader([]byte{0, 1, 2}))}
+		})
+
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, httptest.NewRequest("GET", "/dump", nil))
+		statusCode, body := readResponseRecorder(t, rw)
+		require.Equal(t, http.StatusOK, statusCode)
+		require.Equal(t, "\x00\x01\x02", body)
+		require.Equal(t, "application/octet-stream", rw.Result().Header.Get("Content-Type"))
+	})
+
+	t.Run("no content", func(t *testing.T) {
+		handler := wrapper.Wrap(func() FileResponse { return FileResponse{Name: "missing.csv"} })
+
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, httptest.NewRequest("GET", "/export", nil))
+		statusCode, body := readResponseRecorder(t, rw)
+		require.Equal(t, http.StatusInternalServerError, statusCode)
+		require.Empty(t, body)
+	})
+}
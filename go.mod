@@ -1,13 +1,15 @@
 module github.com/apourchet/httpwrap
 
-go 1.24
+go 1.24.0
 
 require (
-	github.com/gorilla/mux v1.7.2
 	github.com/stretchr/testify v1.3.0
+	golang.org/x/tools v0.42.0
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/mod v0.33.0 // indirect
+	golang.org/x/sync v0.19.0 // indirect
 )
@@ -0,0 +1,290 @@
+// Package httpwrapcheck defines an Analyzer that reports the mistakes in
+// the use of httpwrap that would otherwise only be found at runtime:
+// malformed `http` struct tags, tagged fields whose type cannot be decoded
+// from a string, and functions given to a Wrapper or a Router that do not
+// follow the rules of their stage.
+//
+// It is run with go vet through the httpwrapcheck command:
+//
+//	go install github.com/apourchet/httpwrap/cmd/httpwrapcheck
+//	go vet -vettool=$(which httpwrapcheck) ./...
+package httpwrapcheck
+
+import (
+	"go/ast"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/apourchet/httpwrap/internal/stages"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const _httpwrapPath = "github.com/apourchet/httpwrap"
+
+// Analyzer checks the `http` struct tags and the functions given to the
+// Wrapper and Router methods.
+var Analyzer = &analysis.Analyzer{
+	Name:     "httpwrapcheck",
+	Doc:      "check http struct tags and the signatures of the functions wrapped by httpwrap",
+	URL:      "https://pkg.go.dev/github.com/apourchet/httpwrap/httpwrapcheck",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// _tagKeys are the keys of the `http` tags understood by the Decoder and
+// the StandardResponseWriter, and whether they take a value.
+var _tagKeys = map[string]bool{
+	"header":    true,
+	"query":     true,
+	"segment":   true,
+	"cookie":    true,
+	"basicauth": true,
+	"bearer":    false,
+	"status":    false,
+}
+
+// _stages are the rules that the functions given to the methods of the
+// Wrapper and the Router follow, by the index of the function argument.
+var _stages = map[string]map[int]string{
+	"Wrapper.Wrap":     {0: stages.Main},
+	"Wrapper.Invoke":   {1: stages.Main},
+	"Wrapper.Describe": {0: stages.Main},
+	"Wrapper.Before":   {-1: stages.Before},
+	"Wrapper.Override": {1: stages.Before},
+	"Wrapper.Finally":  {0: stages.After},
+	"Router.Use":       {-1: stages.Before},
+	"Router.Handle":    {2: stages.Main},
+	"Router.GET":       {1: stages.Main},
+	"Router.HEAD":      {1: stages.Main},
+	"Router.POST":      {1: stages.Main},
+	"Router.PUT":       {1: stages.Main},
+	"Router.PATCH":     {1: stages.Main},
+	"Router.DELETE":    {1: stages.Main},
+	"Router.OPTIONS":   {1: stages.Main},
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	filter := []ast.Node{(*ast.StructType)(nil), (*ast.CallExpr)(nil)}
+	inspect.Preorder(filter, func(node ast.Node) {
+		switch node := node.(type) {
+		case *ast.StructType:
+			for _, field := range node.Fields.List {
+				checkField(pass, field)
+			}
+		case *ast.CallExpr:
+			checkCall(pass, node)
+		}
+	})
+	return nil, nil
+}
+
+// checkField reports the malformed `http` tag of the field, or a field type
+// that cannot hold the tagged value.
+func checkField(pass *analysis.Pass, field *ast.Field) {
+	if field.Tag == nil {
+		return
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return
+	}
+	directive, found := reflect.StructTag(tag).Lookup("http")
+	if !found {
+		return
+	}
+
+	tagkey, tagval, hasValue := strings.Cut(directive, "=")
+	takesValue, known := _tagKeys[tagkey]
+	switch {
+	case directive == "":
+		return
+	case !known:
+		if suggestion := closestKey(tagkey); suggestion != "" {
+			pass.Reportf(field.Tag.Pos(), "unrecognized http tag %q, did you mean %q?", tagkey, suggestion)
+		} else {
+			pass.Reportf(field.Tag.Pos(), "unrecognized http tag %q", tagkey)
+		}
+		return
+	case takesValue && (!hasValue || tagval == ""):
+		pass.Reportf(field.Tag.Pos(), "malformed http tag %q: expected %s=<name>", directive, tagkey)
+		return
+	case !takesValue && hasValue:
+		pass.Reportf(field.Tag.Pos(), "malformed http tag %q: %s does not take a value", directive, tagkey)
+		return
+	}
+
+	name, option, _ := strings.Cut(tagval, ",")
+	switch {
+	case tagkey == "cookie" && option != "" && option != "signed" && option != "encrypted":
+		pass.Reportf(field.Tag.Pos(), "unrecognized cookie option %q, expected signed or encrypted", option)
+		return
+	case tagkey == "basicauth" && name != "user" && name != "password":
+		pass.Reportf(field.Tag.Pos(), "unrecognized basicauth value %q, expected user or password", name)
+		return
+	}
+
+	t := pass.TypesInfo.TypeOf(field.Type)
+	if t == nil {
+		return
+	}
+	if tagkey == "status" {
+		if basic, ok := t.Underlying().(*types.Basic); !ok || basic.Info()&types.IsInteger == 0 {
+			pass.Reportf(field.Type.Pos(), "http status field must be an integer, got %s", t)
+		}
+		return
+	}
+
+	// Headers and cookies are also written from the fields of response
+	// structs, which accept interfaces.
+	requestOnly := tagkey != "header" && tagkey != "cookie"
+	if !decodable(t, requestOnly) {
+		pass.Reportf(field.Type.Pos(), "http %s field of type %s cannot be decoded from a string", tagkey, t)
+	}
+}
+
+// decodable returns whether values of the type can be generated from
+// strings by defaults.GenVal, which unmarshals them as JSON.
+func decodable(t types.Type, requestOnly bool) bool {
+	if implementsUnmarshaler(t) {
+		return true
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		return u.Info()&types.IsComplex == 0 && u.Kind() != types.UnsafePointer && u.Kind() != types.Invalid
+	case *types.Pointer:
+		return decodable(u.Elem(), requestOnly)
+	case *types.Slice:
+		return decodable(u.Elem(), requestOnly)
+	case *types.Array:
+		return decodable(u.Elem(), requestOnly)
+	case *types.Map:
+		return decodable(u.Key(), requestOnly) && decodable(u.Elem(), requestOnly)
+	case *types.Interface:
+		return !requestOnly || u.Empty()
+	case *types.Struct:
+		return true
+	}
+	return false
+}
+
+// implementsUnmarshaler returns whether the type or a pointer to it
+// implements json.Unmarshaler or encoding.TextUnmarshaler.
+func implementsUnmarshaler(t types.Type) bool {
+	for _, candidate := range []types.Type{t, types.NewPointer(t)} {
+		methods := types.NewMethodSet(candidate)
+		for _, name := range []string{"UnmarshalJSON", "UnmarshalText"} {
+			if sel := methods.Lookup(nil, name); sel != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkCall reports the functions given to the Wrapper and Router methods
+// that would make them panic.
+func checkCall(pass *analysis.Pass, call *ast.CallExpr) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+	method, ok := pass.TypesInfo.Uses[sel.Sel].(*types.Func)
+	if !ok || method.Pkg() == nil || method.Pkg().Path() != _httpwrapPath {
+		return
+	}
+	recv := method.Signature().Recv()
+	if recv == nil {
+		return
+	}
+	named, ok := types.Unalias(derefPointer(recv.Type())).(*types.Named)
+	if !ok {
+		return
+	}
+
+	stages, found := _stages[named.Obj().Name()+"."+method.Name()]
+	if !found {
+		return
+	}
+	for i, arg := range call.Args {
+		stage, found := stages[i]
+		if !found {
+			stage, found = stages[-1]
+		}
+		if found && !call.Ellipsis.IsValid() {
+			checkFunc(pass, arg, method.Name(), stage)
+		}
+	}
+}
+
+// checkFunc reports the function argument that does not follow the rules
+// of the stage it is given to, the same way the Wrapper would at runtime.
+func checkFunc(pass *analysis.Pass, arg ast.Expr, methodName, stage string) {
+	t := pass.TypesInfo.TypeOf(arg)
+	if t == nil {
+		return
+	}
+	if _, isInterface := t.Underlying().(*types.Interface); isInterface {
+		// The dynamic type of the argument is not known statically.
+		return
+	}
+	if named, ok := types.Unalias(t).(*types.Named); ok &&
+		named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == _httpwrapPath && named.Obj().Name() == "Adapter" {
+		// Adapters are validated with their handler when they are generated.
+		return
+	}
+	sig, ok := t.Underlying().(*types.Signature)
+	if !ok {
+		pass.Reportf(arg.Pos(), "%s expects a function, got %s", methodName, t)
+		return
+	}
+
+	for _, err := range stages.Validate(sig, stage) {
+		pass.Reportf(arg.Pos(), "%v", err)
+	}
+}
+
+func derefPointer(t types.Type) types.Type {
+	if ptr, ok := t.(*types.Pointer); ok {
+		return ptr.Elem()
+	}
+	return t
+}
+
+// closestKey returns the tag key closest to the unrecognized key, if it is
+// close enough to be a typo.
+func closestKey(key string) string {
+	best, bestDistance := "", 3
+	for candidate := range _tagKeys {
+		if distance := editDistance(key, candidate); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between the strings.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,13 @@
+package httpwrapcheck_test
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"github.com/apourchet/httpwrap/httpwrapcheck"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), httpwrapcheck.Analyzer, "a")
+}
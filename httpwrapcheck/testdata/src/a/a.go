@@ -0,0 +1,84 @@
+package a
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/apourchet/httpwrap"
+)
+
+type Params struct {
+	Name    string    `http:"segment=name"`
+	Tags    []string  `http:"query=tags"`
+	Session string    `http:"cookie=session,signed"`
+	Since   time.Time `http:"query=since"`
+	Auth    string    `http:"header=Authorization"`
+	Token   string    `http:"bearer"`
+	User    string    `http:"basicauth=user"`
+	Body    string    `json:"body"`
+	Ignored string    `http:""`
+}
+
+type BadParams struct {
+	Typo     string              `http:"querry=name"`      // want `unrecognized http tag "querry", did you mean "query"\?`
+	Unknown  string              `http:"something=name"`   // want `unrecognized http tag "something"`
+	NoValue  string              `http:"header"`           // want `malformed http tag "header": expected header=<name>`
+	Empty    string              `http:"query="`           // want `malformed http tag "query=": expected query=<name>`
+	Bearer   string              `http:"bearer=token"`     // want `malformed http tag "bearer=token": bearer does not take a value`
+	Cookie   string              `http:"cookie=id,hashed"` // want `unrecognized cookie option "hashed", expected signed or encrypted`
+	Basic    string              `http:"basicauth=login"`  // want `unrecognized basicauth value "login", expected user or password`
+	Callback func()              `http:"query=cb"`         // want `http query field of type func\(\) cannot be decoded from a string`
+	Numbers  []complex128        `http:"query=n"`          // want `http query field of type \[\]complex128 cannot be decoded from a string`
+	Reader   interface{ Read() } `http:"query=r"`          // want `http query field of type interface\{Read\(\)\} cannot be decoded from a string`
+}
+
+type Response struct {
+	Code     int         `http:"status"`
+	Location string      `http:"header=Location"`
+	Any      any         `http:"header=X-Any"`
+	Events   chan string `http:"header=X-Events"` // want `http header field of type chan string cannot be decoded from a string`
+}
+
+type BadResponse struct {
+	Code string `http:"status"` // want `http status field must be an integer, got string`
+}
+
+type DB struct{}
+
+type Any interface{}
+
+func checkAuth(req *http.Request) (DB, error)            { return DB{}, nil }
+func badBefore(v any) error                              { return nil }
+func dupBefore(a, b DB) error                            { return nil }
+func handler(db DB, p Params) (Response, error)          { return Response{}, nil }
+func badMain(db DB, v interface{}) error                 { return nil }
+func dupMain(a, b Params) error                          { return nil }
+func finally(rw http.ResponseWriter, res any, err error) {}
+func dupFinally(a, b error)                              {}
+func namedAny(v Any) error                               { return nil }
+
+func register(w httpwrap.Wrapper, r *httpwrap.Router, dynamic any) {
+	w = w.Before(checkAuth, badBefore)   // want `before input #0 must not be empty interface`
+	w = w.Before(dupBefore)              // want `before input types must be unique: types 0 and 1 are equal`
+	w = w.Override(checkAuth, badBefore) // want `before input #0 must not be empty interface`
+	w = w.Finally(finally)
+	w = w.Finally(dupFinally) // want `after input types must be unique: types 0 and 1 are equal`
+	w = w.Before(dynamic)
+	w = w.Before(namedAny)
+
+	_ = w.Wrap(handler)
+	_ = w.Wrap(namedAny)
+	_ = w.Wrap(badMain) // want `main input #1 must not be empty interface`
+	_ = w.Wrap(httpwrap.Adapter{Handler: handler})
+	_ = w.Wrap("handler")                                    // want `Wrap expects a function, got string`
+	_, _ = w.Invoke(context.Background(), dupMain, Params{}) // want `main input types must be unique: types 0 and 1 are equal`
+
+	_ = w.Describe(handler)
+	_ = w.Describe(badMain) // want `main input #1 must not be empty interface`
+
+	r.Use(badBefore) // want `before input #0 must not be empty interface`
+	r.GET("/pets/{name}", handler)
+	r.POST("/pets", badMain)                                     // want `main input #1 must not be empty interface`
+	r.Handle("PUT", "/pets", func(a, b DB) error { return nil }) // want `main input types must be unique: types 0 and 1 are equal`
+}
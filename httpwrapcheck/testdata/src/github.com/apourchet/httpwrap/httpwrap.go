@@ -0,0 +1,27 @@
+package httpwrap
+
+import (
+	"context"
+	"net/http"
+)
+
+type Wrapper struct{}
+
+func (w Wrapper) Before(fns ...any) Wrapper                                      { return w }
+func (w Wrapper) Override(old, new any) Wrapper                                  { return w }
+func (w Wrapper) Finally(fn any) Wrapper                                         { return w }
+func (w Wrapper) Wrap(fn any) http.Handler                                       { return nil }
+func (w Wrapper) Invoke(ctx context.Context, fn any, inputs ...any) (any, error) { return nil, nil }
+
+func (w Wrapper) Describe(fn any) any { return nil }
+
+type Router struct{}
+
+func (r *Router) Use(befores ...any)                 {}
+func (r *Router) Handle(method, path string, fn any) {}
+func (r *Router) GET(path string, fn any)            {}
+func (r *Router) POST(path string, fn any)           {}
+
+type Adapter struct {
+	Handler any
+}
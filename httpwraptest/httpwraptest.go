@@ -0,0 +1,137 @@
+// Package httpwraptest provides helpers to test the handlers wrapped by
+// httpwrap with typed inputs and outputs instead of raw http requests.
+//
+//	router := httpwrap.NewRouter(httpwrap.NewStandardWrapper().
+//		Before(httpwraptest.Provide(fakeDB)))
+//	router.GET("/pets/{name}", GetPetByName)
+//
+//	pet := httpwraptest.Do[GetPetParams, Pet](t, router, "GET", "/pets/{name}", GetPetParams{Name: "rex"}).
+//		RequireStatus(t, http.StatusOK).
+//		Value
+package httpwraptest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/apourchet/httpwrap"
+)
+
+// Result is the outcome of a request sent to a handler.
+type Result[Out any] struct {
+	// Status is the status code of the response.
+	Status int
+
+	// Header holds the headers of the response.
+	Header http.Header
+
+	// Value is the response decoded into the output type, when the request
+	// succeeded.
+	Value Out
+
+	// Err is the error sent back by the handler, implementing
+	// httpwrap.HTTPError, when the status code is 400 or above.
+	Err error
+}
+
+// Do encodes the input with the `http` tags of its type, sends the request
+// to the handler and decodes the response into the output type the same
+// way httpwrap.Call does. The handler should be the router of the
+// endpoint, so that the segments of the path template are matched. The
+// test fails if the request cannot be built or the response decoded.
+func Do[In, Out any](t testing.TB, handler http.Handler, method, pathTemplate string, in In) Result[Out] {
+	t.Helper()
+	return DoWith[In, Out](t, handler, httpwrap.NewEncoder(), method, pathTemplate, in)
+}
+
+// DoWith is like Do, but encodes the request with the given Encoder.
+func DoWith[In, Out any](
+	t testing.TB,
+	handler http.Handler,
+	encoder *httpwrap.Encoder,
+	method, pathTemplate string,
+	in In,
+) Result[Out] {
+	t.Helper()
+	req, err := encoder.Encode(context.Background(), method, pathTemplate, in)
+	if err != nil {
+		t.Fatalf("httpwraptest: failed to encode request: %v", err)
+	}
+	// Make the request look like one received by a server.
+	req.RequestURI = req.URL.RequestURI()
+	if req.Body == nil {
+		req.Body = http.NoBody
+	}
+
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, req)
+	resp := rw.Result()
+
+	res := Result[Out]{Status: resp.StatusCode, Header: resp.Header}
+	res.Value, res.Err = httpwrap.DecodeResponseWith[Out](resp, encoder)
+	var httpErr httpwrap.HTTPError
+	if res.Err != nil && !errors.As(res.Err, &httpErr) {
+		t.Fatalf("httpwraptest: failed to decode response: %v", res.Err)
+	}
+	return res
+}
+
+// RequireStatus fails the test if the response does not have the status
+// code.
+func (res Result[Out]) RequireStatus(t testing.TB, status int) Result[Out] {
+	t.Helper()
+	if res.Status != status {
+		t.Fatalf("httpwraptest: expected status %d, got %d (error: %v)", status, res.Status, res.Err)
+	}
+	return res
+}
+
+// RequireOK fails the test if the handler sent back an error, and returns
+// the decoded response.
+func (res Result[Out]) RequireOK(t testing.TB) Out {
+	t.Helper()
+	if res.Err != nil {
+		t.Fatalf("httpwraptest: expected no error, got %v", res.Err)
+	}
+	return res.Value
+}
+
+// RequireError fails the test if the handler did not send back an error
+// with the status code, and returns that error.
+func (res Result[Out]) RequireError(t testing.TB, status int) httpwrap.HTTPError {
+	t.Helper()
+	var httpErr httpwrap.HTTPError
+	if !errors.As(res.Err, &httpErr) {
+		t.Fatalf("httpwraptest: expected an error with status %d, got status %d", status, res.Status)
+	} else if httpErr.StatusCode() != status {
+		t.Fatalf("httpwraptest: expected an error with status %d, got %v", status, res.Err)
+	}
+	return httpErr
+}
+
+// Provide returns a before that provides the values to the next stages,
+// taking the place of the values of the same types provided by the earlier
+// befores. Values also satisfy the interface parameters they implement. It
+// is used to inject fakes in place of real dependencies:
+//
+//	wrapper = wrapper.Before(httpwraptest.Provide(fakeDB, fakeClock))
+func Provide(values ...any) any {
+	outTypes := make([]reflect.Type, len(values))
+	outs := make([]reflect.Value, len(values))
+	for i, val := range values {
+		if val == nil {
+			panic(fmt.Errorf("httpwraptest: cannot provide nil value #%d", i))
+		}
+		outTypes[i], outs[i] = reflect.TypeOf(val), reflect.ValueOf(val)
+	}
+
+	fnType := reflect.FuncOf(nil, outTypes, false)
+	return reflect.MakeFunc(fnType, func([]reflect.Value) []reflect.Value {
+		return outs
+	}).Interface()
+}
@@ -0,0 +1,76 @@
+package httpwraptest_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/apourchet/httpwrap"
+	"github.com/apourchet/httpwrap/httpwraptest"
+	"github.com/stretchr/testify/require"
+)
+
+type petStore interface {
+	Get(name string) (string, bool)
+}
+
+type realStore struct{}
+
+func (realStore) Get(string) (string, bool) { return "", false }
+
+type fakeStore map[string]string
+
+func (store fakeStore) Get(name string) (string, bool) {
+	pet, found := store[name]
+	return pet, found
+}
+
+type getPetParams struct {
+	Name  string `http:"segment=name"`
+	Owner string `http:"query=owner"`
+}
+
+type pet struct {
+	Owner   string `http:"header=X-Owner"`
+	Name    string `json:"name"`
+	Species string `json:"species"`
+}
+
+func getPet(store petStore, params getPetParams) (pet, error) {
+	species, found := store.Get(params.Name)
+	if !found {
+		return pet{}, httpwrap.NewHTTPError(http.StatusNotFound, "No pet named %s.", params.Name)
+	}
+	return pet{Owner: params.Owner, Name: params.Name, Species: species}, nil
+}
+
+func newRouter(befores ...any) *httpwrap.Router {
+	wrapper := httpwrap.NewStandardWrapper().
+		Before(func() petStore { return realStore{} }).
+		Before(befores...)
+	router := httpwrap.NewRouter(wrapper)
+	router.GET("/pets/{name}", getPet)
+	return router
+}
+
+func TestDo(t *testing.T) {
+	router := newRouter(httpwraptest.Provide(fakeStore{"rex": "dog"}))
+
+	res := httpwraptest.Do[getPetParams, pet](t, router, "GET", "/pets/{name}", getPetParams{Name: "rex", Owner: "alice"}).
+		RequireStatus(t, http.StatusOK)
+	require.Equal(t, pet{Owner: "alice", Name: "rex", Species: "dog"}, res.RequireOK(t))
+	require.Equal(t, "application/json", res.Header.Get("Content-Type"))
+
+	httpErr := httpwraptest.Do[getPetParams, pet](t, router, "GET", "/pets/{name}", getPetParams{Name: "felix"}).
+		RequireStatus(t, http.StatusNotFound).
+		RequireError(t, http.StatusNotFound)
+	require.Contains(t, fmt.Sprint(httpErr), "No pet named felix.")
+}
+
+func TestProvide(t *testing.T) {
+	router := newRouter()
+	httpwraptest.Do[getPetParams, pet](t, router, "GET", "/pets/{name}", getPetParams{Name: "rex"}).
+		RequireError(t, http.StatusNotFound)
+
+	require.Panics(t, func() { httpwraptest.Provide(nil) })
+}
@@ -0,0 +1,48 @@
+// Package stages checks the signatures of the functions given to a Wrapper
+// with go/types, following the rules that the Wrapper enforces through
+// reflection when the functions are registered. It is shared by the
+// httpwrapcheck analyzer and the httpwrap-gen command.
+package stages
+
+import (
+	"fmt"
+	"go/types"
+)
+
+// Stages of the functions given to a Wrapper.
+const (
+	Before = "before"
+	Main   = "main"
+	After  = "after"
+)
+
+// Validate returns the reasons why the Wrapper would reject a function of
+// the signature given to the stage, worded like the errors of the Wrapper.
+// The first error is the one the Wrapper panics with.
+func Validate(sig *types.Signature, stage string) []error {
+	errs := []error{}
+	params := sig.Params()
+	for i := 0; i < params.Len(); i++ {
+		if stage != After && isEmptyInterface(params.At(i).Type()) {
+			errs = append(errs, fmt.Errorf("%s input #%d must not be empty interface", stage, i))
+		}
+	}
+
+	seen := map[string]int{}
+	for i := 0; i < params.Len(); i++ {
+		key := types.TypeString(params.At(i).Type(), nil)
+		if j, found := seen[key]; found {
+			errs = append(errs, fmt.Errorf("%s input types must be unique: types %d and %d are equal", stage, j, i))
+		}
+		seen[key] = i
+	}
+	return errs
+}
+
+// isEmptyInterface returns whether the type is the unnamed empty interface,
+// e.g: any. Named empty interfaces are accepted by the Wrapper, which tells
+// them apart from the response given to the Finally function.
+func isEmptyInterface(t types.Type) bool {
+	iface, ok := types.Unalias(t).(*types.Interface)
+	return ok && iface.Empty()
+}
@@ -0,0 +1,88 @@
+package stages_test
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/apourchet/httpwrap"
+	"github.com/apourchet/httpwrap/internal/stages"
+	"github.com/stretchr/testify/require"
+)
+
+type DB struct{}
+
+type Store interface{ Name() string }
+
+type Any interface{}
+
+const _source = `package p
+
+type DB struct{}
+
+type Store interface{ Name() string }
+
+type Any interface{}
+
+func valid(db DB, s Store, n int) error { return nil }
+func empty(db DB, v interface{})        {}
+func emptyAny(v any)                    {}
+func duplicate(a DB, b DB)              {}
+func both(v any, a, b DB)               {}
+func errors(a, b error)                 {}
+func named(v Any)                       {}
+`
+
+// TestValidateAgreesWithWrapper checks that Validate rejects the same
+// functions as the Wrapper, with the same error.
+func TestValidateAgreesWithWrapper(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", _source, 0)
+	require.NoError(t, err)
+	config := types.Config{}
+	pkg, err := config.Check("p", fset, []*ast.File{file}, nil)
+	require.NoError(t, err)
+
+	fns := map[string]any{
+		"valid":     func(DB, Store, int) error { return nil },
+		"empty":     func(DB, interface{}) {},
+		"emptyAny":  func(any) {},
+		"duplicate": func(DB, DB) {},
+		"both":      func(any, DB, DB) {},
+		"errors":    func(error, error) {},
+		"named":     func(Any) {},
+	}
+	register := map[string]func(fn any){
+		stages.Before: func(fn any) { httpwrap.New().Before(fn) },
+		stages.Main:   func(fn any) { httpwrap.New().Wrap(fn) },
+		stages.After:  func(fn any) { httpwrap.New().Finally(fn) },
+	}
+
+	for name, fn := range fns {
+		sig := pkg.Scope().Lookup(name).Type().(*types.Signature)
+		for stage, register := range register {
+			t.Run(stage+" "+name, func(t *testing.T) {
+				expected := ""
+				if errs := stages.Validate(sig, stage); len(errs) > 0 {
+					expected = errs[0].Error()
+				}
+				require.Equal(t, expected, registerError(register, fn))
+			})
+		}
+	}
+}
+
+// registerError returns the error that registering the function panics
+// with, if any.
+func registerError(register func(fn any), fn any) (msg string) {
+	defer func() {
+		if r := recover(); r != nil {
+			msg = fmt.Sprint(r)
+		}
+	}()
+	register(fn)
+	return ""
+}
@@ -8,9 +8,17 @@ type mainFn struct {
 	val      reflect.Value
 	inTypes  []reflect.Type
 	outTypes []reflect.Type
+
+	// call is the Call function of the Adapter that the main function was
+	// created from, if any.
+	call func(Inputs) ([]any, error)
 }
 
 func newMain(fn any) (mainFn, error) {
+	if adapter, ok := fn.(Adapter); ok {
+		return newAdapterMain(adapter)
+	}
+
 	val := reflect.ValueOf(fn)
 	fnType := val.Type()
 	inTypes, outTypes := []reflect.Type{}, []reflect.Type{}
@@ -33,6 +41,10 @@ func newMain(fn any) (mainFn, error) {
 }
 
 func (fn mainFn) run(ctx *runctx) any {
+	if fn.call != nil {
+		return fn.runAdapter(ctx)
+	}
+
 	inputs, err := ctx.generate(fn.inTypes)
 	if err != nil {
 		return nil
@@ -50,3 +62,21 @@ func (fn mainFn) run(ctx *runctx) any {
 	}
 	return outs[0].Interface()
 }
+
+func (fn mainFn) runAdapter(ctx *runctx) any {
+	outs, err := fn.call(Inputs{ctx: ctx})
+	if err != nil {
+		ctx.provide(err)
+		return nil
+	}
+	for _, out := range outs {
+		ctx.provide(out)
+	}
+
+	if len(outs) == 0 {
+		return nil
+	} else if len(outs) == 1 && isError(fn.outTypes[0]) {
+		return nil
+	}
+	return outs[0]
+}
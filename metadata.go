@@ -0,0 +1,321 @@
+package httpwrap
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/apourchet/httpwrap/defaults"
+)
+
+var (
+	_cookieType       = reflect.TypeOf(http.Cookie{})
+	_xmlMarshalerType = reflect.TypeOf((*xml.Marshaler)(nil)).Elem()
+)
+
+// responseMeta is the metadata of a response, read from the `http` tags
+// of the fields of the response struct. A `status` field is used as the
+// status code unless it is `0`, `header=<name>` fields are sent as headers
+// and `cookie=<name>` fields as cookies. Cookie fields can either hold an
+// http.Cookie or the value of the cookie, and are signed or encrypted with
+// the `signed` and `encrypted` options, e.g: `cookie=session,signed`.
+type responseMeta struct {
+	status  int
+	headers http.Header
+	cookies []*http.Cookie
+
+	// cookieOptions holds the option of each cookie, if any.
+	cookieOptions []string
+
+	// omitted holds the JSON keys of the tagged fields, which are left
+	// out of the response body.
+	omitted map[string]bool
+}
+
+// readResponseMeta reads the metadata of the response object. It returns
+// false if the object is not a struct with `http` tagged fields.
+func readResponseMeta(res any) (responseMeta, bool, error) {
+	meta := responseMeta{
+		headers: http.Header{},
+		omitted: map[string]bool{},
+	}
+
+	v, valid := defaults.DerefValue(res)
+	if !valid || v.Kind() != reflect.Struct {
+		return meta, false, nil
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		directive, found := field.Tag.Lookup("http")
+		if !found || directive == "" || !field.IsExported() {
+			continue
+		}
+
+		meta.omitted[jsonFieldName(field)] = true
+		if err := meta.readDirective(v.Field(i), directive); err != nil {
+			return meta, true, fmt.Errorf("field %s: %v", field.Name, err)
+		}
+	}
+	return meta, len(meta.omitted) > 0, nil
+}
+
+func (meta *responseMeta) readDirective(field reflect.Value, directive string) error {
+	tagkey, tagval, _ := strings.Cut(directive, "=")
+	switch tagkey {
+	case "status":
+		if field.CanInt() {
+			meta.status = int(field.Int())
+		} else if field.CanUint() {
+			meta.status = int(field.Uint())
+		} else {
+			return fmt.Errorf("status field must be an integer, got %v", field.Type())
+		}
+	case "header":
+		for _, val := range metaStrings(field) {
+			meta.headers.Add(tagval, val)
+		}
+	case "cookie":
+		name, option, _ := strings.Cut(tagval, ",")
+		if option != "" && option != "signed" && option != "encrypted" {
+			return fmt.Errorf("unrecognized cookie option %v", option)
+		}
+		if cookie := metaCookie(field, name); cookie != nil {
+			meta.cookies = append(meta.cookies, cookie)
+			meta.cookieOptions = append(meta.cookieOptions, option)
+		}
+	default:
+		return fmt.Errorf("unrecognized http tag %v", tagkey)
+	}
+	return nil
+}
+
+// protectCookies signs and encrypts the values of the cookies tagged with
+// the `signed` and `encrypted` options.
+func (meta *responseMeta) protectCookies(keys CookieKeys) error {
+	for i, cookie := range meta.cookies {
+		var err error
+		switch meta.cookieOptions[i] {
+		case "signed":
+			meta.cookies[i], err = keys.Signed(cookie)
+		case "encrypted":
+			meta.cookies[i], err = keys.Encrypted(cookie)
+		}
+		if err != nil {
+			return fmt.Errorf("cookie %s: %v", cookie.Name, err)
+		}
+	}
+	return nil
+}
+
+// write sets the headers and cookies of the response. The status code is
+// left to the caller.
+func (meta responseMeta) write(w http.ResponseWriter) {
+	for key, vals := range meta.headers {
+		for _, val := range vals {
+			w.Header().Add(key, val)
+		}
+	}
+	for _, cookie := range meta.cookies {
+		http.SetCookie(w, cookie)
+	}
+}
+
+// body returns the JSON encoding of the response without the fields that
+// were used as metadata.
+func (meta responseMeta) body(res any) (json.RawMessage, error) {
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(res); err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(buf)
+	if delim, err := decoder.Token(); err != nil {
+		return nil, err
+	} else if delim != json.Delim('{') {
+		// The type marshals itself into something that is not an object,
+		// there are no fields to omit.
+		return json.RawMessage(bytes.TrimSpace(buf.Bytes())), nil
+	}
+
+	out := &bytes.Buffer{}
+	out.WriteByte('{')
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+
+		key, _ := token.(string)
+		if meta.omitted[key] {
+			continue
+		} else if out.Len() > 1 {
+			out.WriteByte(',')
+		}
+		encodedKey, _ := json.Marshal(key)
+		out.Write(encodedKey)
+		out.WriteByte(':')
+		out.Write(value)
+	}
+	out.WriteByte('}')
+	return out.Bytes(), nil
+}
+
+// encodeXMLBody encodes the response struct as XML without the fields that
+// were used as metadata. Types that marshal themselves are encoded as they
+// are.
+func encodeXMLBody(encoder *xml.Encoder, res any) error {
+	v, _ := defaults.DerefValue(res)
+	t := v.Type()
+	if t.Implements(_xmlMarshalerType) || reflect.PointerTo(t).Implements(_xmlMarshalerType) {
+		return encoder.Encode(res)
+	}
+
+	// Like promoted fields, the shallowest field of a name hides the others,
+	// and fields of the same depth hide each other.
+	all, shallowest, count := xmlBodyFields(v, 0), map[string]int{}, map[string]int{}
+	for _, f := range all {
+		if depth, found := shallowest[f.field.Name]; !found || f.depth < depth {
+			shallowest[f.field.Name], count[f.field.Name] = f.depth, 0
+		}
+		if f.depth == shallowest[f.field.Name] {
+			count[f.field.Name]++
+		}
+	}
+
+	fields, values, named := []reflect.StructField{}, []reflect.Value{}, false
+	for _, f := range all {
+		if f.depth != shallowest[f.field.Name] || count[f.field.Name] > 1 {
+			continue
+		}
+		named = named || f.field.Name == "XMLName"
+		fields = append(fields, f.field)
+		values = append(values, f.value)
+	}
+
+	body := reflect.New(reflect.StructOf(fields)).Elem()
+	for i, val := range values {
+		body.Field(i).Set(val)
+	}
+	if named {
+		return encoder.Encode(body.Interface())
+	}
+	// The body has no type name to name its element after.
+	return encoder.EncodeElement(body.Interface(), xml.StartElement{Name: xml.Name{Local: t.Name()}})
+}
+
+// xmlBodyField is a field of the XML body of a response.
+type xmlBodyField struct {
+	field reflect.StructField
+	value reflect.Value
+	depth int
+}
+
+// xmlBodyFields returns the fields of the struct that are encoded in its XML
+// body, without the metadata fields of the response. Embedded structs are
+// flattened the way encoding/xml does, since reflect.StructOf cannot embed
+// types that have methods.
+func xmlBodyFields(v reflect.Value, depth int) []xmlBodyField {
+	fields, t := []xmlBodyField{}, v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+		if field.Anonymous && field.Tag.Get("xml") == "" {
+			if value.Kind() == reflect.Ptr && value.Type().Elem().Kind() == reflect.Struct {
+				if value.IsNil() {
+					continue
+				}
+				value = value.Elem()
+			}
+			if value.Kind() == reflect.Struct {
+				fields = append(fields, xmlBodyFields(value, depth+1)...)
+				continue
+			}
+		}
+
+		// Only the fields of the response itself are metadata.
+		if directive, found := field.Tag.Lookup("http"); !field.IsExported() || (depth == 0 && found && directive != "") {
+			continue
+		}
+		field.Anonymous = false
+		fields = append(fields, xmlBodyField{field: field, value: value, depth: depth})
+	}
+	return fields
+}
+
+// jsonFieldName returns the key of the struct field in its JSON encoding.
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// metaStrings returns the string values of a header field. Slices produce
+// one value per element, and empty values are skipped.
+func metaStrings(field reflect.Value) []string {
+	for field.Kind() == reflect.Ptr || field.Kind() == reflect.Interface {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+
+	if field.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.Uint8 {
+		vals := []string{}
+		for i := 0; i < field.Len(); i++ {
+			vals = append(vals, metaStrings(field.Index(i))...)
+		}
+		return vals
+	}
+
+	if t, ok := field.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return nil
+		}
+		return []string{t.UTC().Format(http.TimeFormat)}
+	}
+
+	val := fmt.Sprint(field.Interface())
+	if val == "" {
+		return nil
+	}
+	return []string{val}
+}
+
+// metaCookie returns the cookie that a cookie field holds, or nil if the
+// field is empty.
+func metaCookie(field reflect.Value, name string) *http.Cookie {
+	for field.Kind() == reflect.Ptr || field.Kind() == reflect.Interface {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+
+	if field.Type() == _cookieType {
+		cookie := field.Interface().(http.Cookie)
+		if cookie.Name == "" {
+			cookie.Name = name
+		}
+		return &cookie
+	}
+
+	vals := metaStrings(field)
+	if len(vals) == 0 {
+		return nil
+	}
+	return &http.Cookie{Name: name, Value: vals[0]}
+}
@@ -0,0 +1,124 @@
+package httpwrap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type taggedResponse struct {
+	Status       int          `http:"status"`
+	ETag         string       `http:"header=ETag"`
+	LastModified time.Time    `http:"header=Last-Modified"`
+	Links        []string     `http:"header=Link"`
+	Session      *http.Cookie `http:"cookie=session"`
+	Theme        string       `http:"cookie=theme"`
+	Value        int          `json:"value"`
+	Name         string
+}
+
+func TestResponseMeta(t *testing.T) {
+	t.Run("tagged response", func(t *testing.T) {
+		modified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		handler := NewStandardWrapper().Wrap(func() *taggedResponse {
+			return &taggedResponse{
+				Status:       http.StatusCreated,
+				ETag:         `"v1"`,
+				LastModified: modified,
+				Links:        []string{"</a>", "</b>"},
+				Session:      &http.Cookie{Value: "abc", HttpOnly: true},
+				Theme:        "dark",
+				Value:        42,
+				Name:         "<pet>",
+			}
+		})
+
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, httptest.NewRequest("GET", "/endpoint", nil))
+		statusCode, body := readResponseRecorder(t, rw)
+		require.Equal(t, http.StatusCreated, statusCode)
+		require.Equal(t, `{"value":42,"Name":"<pet>"}`, body)
+
+		result := rw.Result()
+		require.Equal(t, `"v1"`, result.Header.Get("ETag"))
+		require.Equal(t, "Tue, 02 Jan 2024 03:04:05 GMT", result.Header.Get("Last-Modified"))
+		require.Equal(t, []string{"</a>", "</b>"}, result.Header.Values("Link"))
+
+		cookies := result.Cookies()
+		require.Len(t, cookies, 2)
+		require.Equal(t, "session", cookies[0].Name)
+		require.Equal(t, "abc", cookies[0].Value)
+		require.True(t, cookies[0].HttpOnly)
+		require.Equal(t, "theme", cookies[1].Name)
+		require.Equal(t, "dark", cookies[1].Value)
+	})
+
+	t.Run("empty metadata", func(t *testing.T) {
+		handler := NewStandardWrapper().Wrap(func() taggedResponse {
+			return taggedResponse{Value: 1}
+		})
+
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, httptest.NewRequest("GET", "/endpoint", nil))
+		statusCode, body := readResponseRecorder(t, rw)
+		require.Equal(t, http.StatusOK, statusCode)
+		require.Equal(t, `{"value":1,"Name":""}`, body)
+		require.Empty(t, rw.Result().Header.Get("ETag"))
+		require.Empty(t, rw.Result().Cookies())
+	})
+
+	t.Run("protected cookies", func(t *testing.T) {
+		type protectedResponse struct {
+			Session string `http:"cookie=session,signed"`
+			Secret  string `http:"cookie=secret,encrypted"`
+		}
+		keys := CookieKeys{
+			Signing:    [][]byte{[]byte("signing-key")},
+			Encryption: [][]byte{[]byte("0123456789abcdef")},
+		}
+		main := func() protectedResponse { return protectedResponse{Session: "alice", Secret: "42"} }
+
+		handler := New().Finally(StandardResponseWriter(WithCookieKeys(keys))).Wrap(main)
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, httptest.NewRequest("GET", "/endpoint", nil))
+		require.Equal(t, http.StatusOK, rw.Code)
+
+		cookies := rw.Result().Cookies()
+		require.Len(t, cookies, 2)
+		require.Equal(t, "session", cookies[0].Name)
+		session, err := keys.Verify("session", cookies[0].Value)
+		require.NoError(t, err)
+		require.Equal(t, "alice", session)
+		require.Equal(t, "secret", cookies[1].Name)
+		secret, err := keys.Decrypt("secret", cookies[1].Value)
+		require.NoError(t, err)
+		require.Equal(t, "42", secret)
+
+		// Without keys, the cookies cannot be protected.
+		rw = httptest.NewRecorder()
+		NewStandardWrapper().Wrap(main).ServeHTTP(rw, httptest.NewRequest("GET", "/endpoint", nil))
+		require.Equal(t, http.StatusInternalServerError, rw.Code)
+		require.Empty(t, rw.Result().Cookies())
+	})
+
+	t.Run("bad cookie option", func(t *testing.T) {
+		type badResponse struct {
+			Session string `http:"cookie=session,hashed"`
+		}
+		_, found, err := readResponseMeta(badResponse{Session: "alice"})
+		require.True(t, found)
+		require.Error(t, err)
+	})
+
+	t.Run("bad status field", func(t *testing.T) {
+		type badResponse struct {
+			Status string `http:"status"`
+		}
+		_, found, err := readResponseMeta(badResponse{Status: "201"})
+		require.True(t, found)
+		require.Error(t, err)
+	})
+}
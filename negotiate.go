@@ -0,0 +1,150 @@
+package httpwrap
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// CodecRegistry holds the codecs available to encode responses, in order of
+// preference of the server.
+type CodecRegistry struct {
+	codecs []Codec
+}
+
+// NewCodecRegistry returns a registry holding the given codecs.
+func NewCodecRegistry(codecs ...Codec) *CodecRegistry {
+	registry := &CodecRegistry{}
+	for _, codec := range codecs {
+		registry.Register(codec)
+	}
+	return registry
+}
+
+// DefaultCodecs returns a registry with the JSON, XML, plain text and CSV
+// codecs, JSON being the preferred one.
+func DefaultCodecs() *CodecRegistry {
+	return NewCodecRegistry(JSONCodec(), XMLCodec(), TextCodec(), CSVCodec())
+}
+
+// Register adds the codec to the registry and returns the registry. A codec
+// that produces the same media type as an existing one replaces it.
+func (registry *CodecRegistry) Register(codec Codec) *CodecRegistry {
+	mediaType := baseMediaType(codec.ContentType())
+	for i, existing := range registry.codecs {
+		if baseMediaType(existing.ContentType()) == mediaType {
+			registry.codecs[i] = codec
+			return registry
+		}
+	}
+	registry.codecs = append(registry.codecs, codec)
+	return registry
+}
+
+// ContentTypes returns the media types that the registry can produce.
+func (registry *CodecRegistry) ContentTypes() []string {
+	types := make([]string, len(registry.codecs))
+	for i, codec := range registry.codecs {
+		types[i] = baseMediaType(codec.ContentType())
+	}
+	return types
+}
+
+// Negotiate returns the codec that best satisfies the Accept header among
+// those that can encode the response object. Codecs are ranked by the
+// quality value of the most specific media range that matches them, ties
+// being broken by the order of the registry. An empty or unparsable Accept
+// header accepts any media type.
+func (registry *CodecRegistry) Negotiate(accept string, res any) (Codec, bool) {
+	ranges := parseAccept(accept)
+	if len(ranges) == 0 {
+		ranges = []mediaRange{{mediaType: "*", subType: "*", quality: 1}}
+	}
+
+	var best Codec
+	bestQuality := 0.0
+	for _, codec := range registry.codecs {
+		if !codec.CanEncode(res) {
+			continue
+		}
+		if quality := acceptQuality(ranges, baseMediaType(codec.ContentType())); quality > bestQuality {
+			best, bestQuality = codec, quality
+		}
+	}
+	return best, best != nil
+}
+
+// NegotiatingResponseWriter behaves like the StandardResponseWriter, except
+// that response objects are encoded with the codec that best matches the
+// Accept header of the request. If none of the codecs are acceptable, a 406
+// Not Acceptable is sent back. A nil registry uses the DefaultCodecs.
+func NegotiatingResponseWriter(registry *CodecRegistry, opts ...ResponseWriterOption) ResponseWriter {
+	return StandardResponseWriter(append([]ResponseWriterOption{WithCodecs(registry)}, opts...)...)
+}
+
+// mediaRange is a single entry of an Accept header, e.g: text/*;q=0.8.
+type mediaRange struct {
+	mediaType string
+	subType   string
+	quality   float64
+}
+
+// parseAccept parses the media ranges of an Accept header, skipping the
+// malformed ones.
+func parseAccept(accept string) []mediaRange {
+	ranges := []mediaRange{}
+	for _, part := range strings.Split(accept, ",") {
+		full, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		mediaType, subType, found := strings.Cut(full, "/")
+		if !found || (mediaType == "*" && subType != "*") {
+			continue
+		}
+
+		quality := 1.0
+		if q, found := params["q"]; found {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil || quality < 0 || quality > 1 {
+				continue
+			}
+		}
+		ranges = append(ranges, mediaRange{
+			mediaType: mediaType,
+			subType:   subType,
+			quality:   quality,
+		})
+	}
+	return ranges
+}
+
+// acceptQuality returns the quality value of the most specific media range
+// that matches the media type, or 0 if none match.
+func acceptQuality(ranges []mediaRange, full string) float64 {
+	mediaType, subType, _ := strings.Cut(full, "/")
+	quality, specificity := 0.0, -1
+	for _, r := range ranges {
+		current := 0
+		switch {
+		case r.mediaType == mediaType && r.subType == subType:
+			current = 2
+		case r.mediaType == mediaType && r.subType == "*":
+			current = 1
+		case r.mediaType == "*" && r.subType == "*":
+			current = 0
+		default:
+			continue
+		}
+		if current > specificity {
+			quality, specificity = r.quality, current
+		}
+	}
+	return quality
+}
+
+// baseMediaType returns the media type of a Content-Type value, without its
+// parameters.
+func baseMediaType(contentType string) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
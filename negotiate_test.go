@@ -0,0 +1,104 @@
+package httpwrap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type csvPet struct {
+	Name     string `json:"name"`
+	Category int    `csv:"cat"`
+	Ignored  string `json:"-"`
+}
+
+type xmlPet struct {
+	Name string `xml:"name"`
+}
+
+func TestNegotiate(t *testing.T) {
+	registry := DefaultCodecs()
+
+	t.Run("quality values", func(t *testing.T) {
+		codec, found := registry.Negotiate("text/csv;q=0.5, application/xml;q=0.9", []csvPet{})
+		require.True(t, found)
+		require.Equal(t, "text/csv; charset=utf-8", codec.ContentType())
+
+		codec, found = registry.Negotiate("text/csv;q=0.5, application/xml;q=0.9", xmlPet{})
+		require.True(t, found)
+		require.Equal(t, "application/xml", codec.ContentType())
+	})
+
+	t.Run("specificity", func(t *testing.T) {
+		codec, found := registry.Negotiate("text/*;q=0.1, text/csv;q=0.9, */*;q=0.2", []csvPet{})
+		require.True(t, found)
+		require.Equal(t, "text/csv; charset=utf-8", codec.ContentType())
+
+		codec, found = registry.Negotiate("application/json;q=0, */*", "hello")
+		require.True(t, found)
+		require.Equal(t, "text/plain; charset=utf-8", codec.ContentType())
+	})
+
+	t.Run("empty accept", func(t *testing.T) {
+		codec, found := registry.Negotiate("", []csvPet{})
+		require.True(t, found)
+		require.Equal(t, "application/json", codec.ContentType())
+	})
+
+	t.Run("nothing acceptable", func(t *testing.T) {
+		_, found := registry.Negotiate("text/csv", xmlPet{})
+		require.False(t, found)
+
+		_, found = registry.Negotiate("application/json;q=0", xmlPet{})
+		require.False(t, found)
+	})
+
+	t.Run("register replaces media type", func(t *testing.T) {
+		registry := DefaultCodecs().Register(xmlCodec{})
+		require.Equal(t, []string{"application/json", "application/xml", "text/plain", "text/csv"}, registry.ContentTypes())
+	})
+}
+
+func TestNegotiatingResponseWriter(t *testing.T) {
+	wrapper := NewStandardWrapper().Finally(NegotiatingResponseWriter(nil))
+	handler := wrapper.Wrap(func() []csvPet {
+		return []csvPet{{Name: "rex", Category: 1}, {Name: "tom, jr", Category: 2}}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/pets", nil)
+		req.Header.Set("Accept", "text/csv")
+		handler.ServeHTTP(rw, req)
+
+		statusCode, body := readResponseRecorder(t, rw)
+		require.Equal(t, http.StatusOK, statusCode)
+		require.Equal(t, "name,cat\nrex,1\n\"tom, jr\",2", body)
+		require.Equal(t, "text/csv; charset=utf-8", rw.Result().Header.Get("Content-Type"))
+		require.Equal(t, "Accept", rw.Result().Header.Get("Vary"))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/pets", nil)
+		req.Header.Set("Accept", "application/json, text/csv;q=0.5")
+		handler.ServeHTTP(rw, req)
+
+		statusCode, body := readResponseRecorder(t, rw)
+		require.Equal(t, http.StatusOK, statusCode)
+		require.Equal(t, `[{"name":"rex","Category":1},{"name":"tom, jr","Category":2}]`, body)
+		require.Equal(t, "application/json", rw.Result().Header.Get("Content-Type"))
+	})
+
+	t.Run("not acceptable", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/pets", nil)
+		req.Header.Set("Accept", "image/png")
+		handler.ServeHTTP(rw, req)
+
+		statusCode, _ := readResponseRecorder(t, rw)
+		require.Equal(t, http.StatusNotAcceptable, statusCode)
+	})
+}
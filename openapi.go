@@ -0,0 +1,404 @@
+package httpwrap
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+var (
+	_requestType      = reflect.TypeOf((*http.Request)(nil))
+	_readerType       = reflect.TypeOf((*io.Reader)(nil)).Elem()
+	_httpResponseType = reflect.TypeOf((*HTTPResponse)(nil)).Elem()
+	_fileResponseType = reflect.TypeOf(FileResponse{})
+)
+
+// _pathSegment matches the wildcards of the ServeMux patterns.
+var _pathSegment = regexp.MustCompile(`\{([^{}.]*)(\.\.\.)?\}`)
+
+// OpenAPIDocument is an OpenAPI 3.1 document describing the routes of a
+// Router. It implements `http.Handler` and serves itself as JSON.
+type OpenAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       OpenAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*OpenAPIOperation `json:"paths"`
+	Components *OpenAPIComponents                      `json:"components,omitempty"`
+}
+
+// OpenAPIInfo holds the metadata of the API.
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPIComponents holds the schemas of the named structs used by the API
+// and the security schemes of its credentials.
+type OpenAPIComponents struct {
+	Schemas         map[string]*Schema               `json:"schemas,omitempty"`
+	SecuritySchemes map[string]OpenAPISecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// OpenAPIOperation describes a single route.
+type OpenAPIOperation struct {
+	OperationID string                     `json:"operationId,omitempty"`
+	Parameters  []OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]OpenAPIResponse `json:"responses"`
+	Security    []map[string][]string      `json:"security,omitempty"`
+}
+
+// OpenAPIParameter describes a value read from the path, query, headers or
+// cookies of the requests.
+type OpenAPIParameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// OpenAPIRequestBody describes the body of the requests.
+type OpenAPIRequestBody struct {
+	Required bool                        `json:"required,omitempty"`
+	Content  map[string]OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIResponse describes a response of an operation.
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Headers     map[string]OpenAPIHeader    `json:"headers,omitempty"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIMediaType holds the schema of a body for a media type.
+type OpenAPIMediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// OpenAPIHeader describes a header of a response.
+type OpenAPIHeader struct {
+	Schema *Schema `json:"schema"`
+}
+
+// OpenAPISecurityScheme describes the credentials sent with the requests.
+type OpenAPISecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme"`
+}
+
+// OpenAPI generates the OpenAPI 3.1 document of the routes registered on
+// the router. The document is built by reflection on the functions given
+// to Handle and on the befores of their wrappers:
+//
+// - The inputs that no before provides are read from the request. The
+// fields of those structs with `http` tags become parameters, and the other
+// fields make up the JSON request body. The `basicauth` and `bearer` tags
+// add a security requirement to the operation.
+//
+// - The first non-error output of the main function describes the success
+// response, with its `header` tags as response headers. Streams, readers and
+// files are described with their media types.
+//
+// - Operations that decode the request can fail with a 400, the ones that
+// read credentials with a 401, and the ones with functions returning errors
+// have a default error response.
+//
+// It assumes that the wrappers decode requests with a Decoder. Routes
+// registered without a method are left out of the document.
+//
+//	mux := http.NewServeMux()
+//	mux.Handle("/", router)
+//	mux.Handle("GET /openapi.json", router.OpenAPI(info))
+func (r *Router) OpenAPI(info OpenAPIInfo) *OpenAPIDocument {
+	doc := &OpenAPIDocument{
+		OpenAPI: "3.1.0",
+		Info:    info,
+		Paths:   map[string]map[string]*OpenAPIOperation{},
+	}
+
+	schemas := newSchemaGenerator()
+	security := map[string]OpenAPISecurityScheme{}
+	operationIDs := map[string]bool{}
+	for _, reg := range *r.routes {
+		if reg.Method == "" {
+			continue
+		}
+
+		path := openAPIPath(reg.Path)
+		op := newOperation(reg, path, schemas, security)
+		if op.OperationID != "" && operationIDs[op.OperationID] {
+			op.OperationID = ""
+		}
+		operationIDs[op.OperationID] = true
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]*OpenAPIOperation{}
+		}
+		doc.Paths[path][strings.ToLower(reg.Method)] = op
+	}
+
+	if len(schemas.components) > 0 || len(security) > 0 {
+		doc.Components = &OpenAPIComponents{
+			Schemas:         schemas.components,
+			SecuritySchemes: security,
+		}
+	}
+	return doc
+}
+
+// ServeHTTP implements `http.Handler`.
+func (doc *OpenAPIDocument) ServeHTTP(rw http.ResponseWriter, _ *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(rw).Encode(doc)
+}
+
+// newOperation describes the route.
+func newOperation(
+	reg registration,
+	path string,
+	schemas *schemaGenerator,
+	security map[string]OpenAPISecurityScheme,
+) *OpenAPIOperation {
+	handler := handlerOf(reg.Handler)
+	op := &OpenAPIOperation{
+		OperationID: operationID(handler),
+		Responses:   map[string]OpenAPIResponse{},
+	}
+
+	inputs, fails := requestInputs(reg.wrapper, handler)
+	body := []*Schema{}
+	declared := map[string]bool{}
+	for _, t := range inputs {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			body = append(body, schemas.schema(t))
+			continue
+		}
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			directive := field.Tag.Get("http")
+			if directive == "" || !field.IsExported() {
+				continue
+			}
+
+			tagkey, tagval, _ := strings.Cut(directive, "=")
+			name, _, _ := strings.Cut(tagval, ",")
+			switch tagkey {
+			case "segment":
+				declared[name] = true
+				op.addParameter(name, "path", true, schemas.schema(field.Type))
+			case "query", "header", "cookie":
+				op.addParameter(name, tagkey, false, schemas.schema(field.Type))
+			case "basicauth":
+				security["basicAuth"] = OpenAPISecurityScheme{Type: "http", Scheme: "basic"}
+				op.addSecurity("basicAuth")
+			case "bearer":
+				security["bearerAuth"] = OpenAPISecurityScheme{Type: "http", Scheme: "bearer"}
+				op.addSecurity("bearerAuth")
+			}
+		}
+		if len(bodyFields(t)) > 0 {
+			body = append(body, schemas.schema(t))
+		}
+	}
+
+	// Every wildcard of the path must be declared, even if no struct reads
+	// it.
+	for _, match := range _pathSegment.FindAllStringSubmatch(path, -1) {
+		if !declared[match[1]] {
+			op.addParameter(match[1], "path", true, &Schema{Type: "string"})
+		}
+	}
+
+	if len(body) > 0 && reg.Method != http.MethodGet && reg.Method != http.MethodHead {
+		schema := body[0]
+		if len(body) > 1 {
+			schema = &Schema{AllOf: body}
+		}
+		op.RequestBody = &OpenAPIRequestBody{
+			Content: map[string]OpenAPIMediaType{"application/json": {Schema: schema}},
+		}
+	}
+
+	_, outTypes := typesOf(handler)
+	op.addSuccess(outTypes, schemas)
+	if len(inputs) > 0 {
+		op.Responses["400"] = OpenAPIResponse{Description: http.StatusText(http.StatusBadRequest)}
+	}
+	if len(op.Security) > 0 {
+		op.Responses["401"] = OpenAPIResponse{Description: http.StatusText(http.StatusUnauthorized)}
+	}
+	if fails {
+		op.Responses["default"] = OpenAPIResponse{Description: "Error"}
+	}
+	return op
+}
+
+func (op *OpenAPIOperation) addParameter(name, in string, required bool, schema *Schema) {
+	for _, param := range op.Parameters {
+		if param.Name == name && param.In == in {
+			return
+		}
+	}
+	op.Parameters = append(op.Parameters, OpenAPIParameter{
+		Name:     name,
+		In:       in,
+		Required: required,
+		Schema:   schema,
+	})
+}
+
+func (op *OpenAPIOperation) addSecurity(scheme string) {
+	for _, requirement := range op.Security {
+		if _, found := requirement[scheme]; found {
+			return
+		}
+	}
+	op.Security = append(op.Security, map[string][]string{scheme: {}})
+}
+
+// addSuccess describes the response sent with the first non-error output
+// of the main function.
+func (op *OpenAPIOperation) addSuccess(outTypes []reflect.Type, schemas *schemaGenerator) {
+	var res reflect.Type
+	for _, t := range outTypes {
+		if !isError(t) {
+			res = t
+			break
+		}
+	}
+
+	switch {
+	case res == nil:
+		op.Responses["200"] = OpenAPIResponse{Description: http.StatusText(http.StatusOK)}
+	case res.Implements(_httpResponseType):
+		op.Responses["2XX"] = OpenAPIResponse{Description: "Success"}
+	case isStreamType(res):
+		var item *Schema
+		if res.Kind() == reflect.Chan {
+			item = schemas.schema(res.Elem())
+		} else {
+			item = schemas.schema(res.In(0).In(0))
+		}
+		op.Responses["200"] = OpenAPIResponse{
+			Description: http.StatusText(http.StatusOK),
+			Content: map[string]OpenAPIMediaType{
+				contentTypeNDJSON: {Schema: item},
+				contentTypeSSE:    {Schema: item},
+			},
+		}
+	case res == _fileResponseType || res == reflect.PointerTo(_fileResponseType) || res.Implements(_readerType):
+		op.Responses["200"] = OpenAPIResponse{
+			Description: http.StatusText(http.StatusOK),
+			Content: map[string]OpenAPIMediaType{
+				"application/octet-stream": {Schema: &Schema{Type: "string", Format: "binary"}},
+			},
+		}
+	default:
+		op.addEncoded(res, schemas)
+	}
+}
+
+// addEncoded describes the response sent with the encoded result, along
+// with the metadata of its `http` tags.
+func (op *OpenAPIOperation) addEncoded(res reflect.Type, schemas *schemaGenerator) {
+	code := "200"
+	response := OpenAPIResponse{
+		Description: http.StatusText(http.StatusOK),
+		Content: map[string]OpenAPIMediaType{
+			"application/json": {Schema: schemas.schema(res)},
+		},
+	}
+
+	for res.Kind() == reflect.Ptr {
+		res = res.Elem()
+	}
+	if res.Kind() == reflect.Struct {
+		for i := 0; i < res.NumField(); i++ {
+			field := res.Field(i)
+			tagkey, tagval, _ := strings.Cut(field.Tag.Get("http"), "=")
+			switch tagkey {
+			case "status":
+				code, response.Description = "2XX", "Success"
+			case "header":
+				if response.Headers == nil {
+					response.Headers = map[string]OpenAPIHeader{}
+				}
+				response.Headers[tagval] = OpenAPIHeader{Schema: schemas.schema(field.Type)}
+			}
+		}
+	}
+	op.Responses[code] = response
+}
+
+// requestInputs returns the input types of the befores and of the main
+// function that are constructed with the RequestReader, because no earlier
+// stage provides them, and whether any of those functions returns an error.
+func requestInputs(w Wrapper, fn any) ([]reflect.Type, bool) {
+	provided := []reflect.Type{_requestType, reflect.TypeOf(&responseRecorder{}), reflect.TypeOf(ResponseInfo{})}
+	isProvided := func(t reflect.Type) bool {
+		for _, p := range provided {
+			if p == t || (t.Kind() == reflect.Interface && p.Implements(t)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	inputs, fails := []reflect.Type{}, false
+	collect := func(inTypes, outTypes []reflect.Type) {
+		for _, t := range inTypes {
+			if !isProvided(t) && t.Kind() != reflect.Interface && !containsType(inputs, t) {
+				inputs = append(inputs, t)
+			}
+		}
+		for _, t := range outTypes {
+			fails = fails || isError(t)
+		}
+		provided = append(provided, outTypes...)
+	}
+
+	for _, before := range w.befores {
+		collect(before.inTypes, before.outTypes)
+	}
+	collect(typesOf(fn))
+	return inputs, fails
+}
+
+func containsType(types []reflect.Type, t reflect.Type) bool {
+	for _, other := range types {
+		if other == t {
+			return true
+		}
+	}
+	return false
+}
+
+// openAPIPath converts a ServeMux pattern path to an OpenAPI path, e.g:
+// /files/{path...} becomes /files/{path}.
+func openAPIPath(path string) string {
+	if i := strings.Index(path, "/"); i > 0 {
+		path = path[i:]
+	}
+	path = strings.TrimSuffix(path, "{$}")
+	return _pathSegment.ReplaceAllString(path, "{$1}")
+}
+
+// operationID returns the name of the function, or an empty string for
+// anonymous functions.
+func operationID(fn any) string {
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+	name = strings.TrimSuffix(name, "-fm")
+	name = name[strings.LastIndex(name, ".")+1:]
+	if strings.HasPrefix(name, "func") && strings.Trim(name[4:], "0123456789") == "" {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,122 @@
+package httpwrap
+
+import (
+	"encoding/json"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type apiCredentials struct {
+	Token string `http:"bearer"`
+}
+
+type apiUser struct {
+	Name string
+}
+
+type apiPet struct {
+	Name     string    `json:"name"`
+	Born     time.Time `json:"born"`
+	Tags     []string  `json:"tags,omitempty"`
+	Parent   *apiPet   `json:"parent"`
+	internal int
+}
+
+type apiGetPetParams struct {
+	Name    string `http:"segment=name"`
+	Verbose bool   `http:"query=verbose"`
+}
+
+type apiCreatePetParams struct {
+	RequestID string `http:"header=X-Request-ID"`
+	apiPet
+}
+
+type apiCreatedPet struct {
+	Status   int    `http:"status"`
+	Location string `http:"header=Location"`
+	ID       int64  `json:"id"`
+	Litter   int    `json:"litter"`
+	Version  int32  `json:"version"`
+}
+
+func authenticate(creds apiCredentials) (apiUser, error) { return apiUser{Name: creds.Token}, nil }
+
+func GetPet(apiGetPetParams) (apiPet, error) { return apiPet{}, nil }
+
+func CreatePet(apiUser, apiCreatePetParams) (apiCreatedPet, error) { return apiCreatedPet{}, nil }
+
+func TestRouterOpenAPI(t *testing.T) {
+	router := NewRouter(NewStandardWrapper().Before(authenticate))
+	router.Handle("GET", "/pets/{name}", GetPet)
+	router.Handle("POST", "/pets", CreatePet)
+	router.Handle("GET", "/pets/{name}/events/{rest...}", func(apiUser) iter.Seq[apiPet] { return nil })
+	router.Handle("", "/health", func() {})
+
+	doc := router.OpenAPI(OpenAPIInfo{Title: "Pets", Version: "1.0.0"})
+	require.Equal(t, "3.1.0", doc.OpenAPI)
+	require.Len(t, doc.Paths, 3)
+
+	getPet := doc.Paths["/pets/{name}"]["get"]
+	require.Equal(t, "GetPet", getPet.OperationID)
+	require.Equal(t, []OpenAPIParameter{
+		{Name: "name", In: "path", Required: true, Schema: &Schema{Type: "string"}},
+		{Name: "verbose", In: "query", Schema: &Schema{Type: "boolean"}},
+	}, getPet.Parameters)
+	require.Nil(t, getPet.RequestBody)
+	require.Equal(t, []map[string][]string{{"bearerAuth": {}}}, getPet.Security)
+	require.Equal(t, &Schema{Ref: "#/components/schemas/apiPet"},
+		getPet.Responses["200"].Content["application/json"].Schema)
+	require.Contains(t, getPet.Responses, "400")
+	require.Contains(t, getPet.Responses, "401")
+	require.Contains(t, getPet.Responses, "default")
+
+	createPet := doc.Paths["/pets"]["post"]
+	require.Equal(t, []OpenAPIParameter{
+		{Name: "X-Request-ID", In: "header", Schema: &Schema{Type: "string"}},
+	}, createPet.Parameters)
+	require.Equal(t, &Schema{Ref: "#/components/schemas/apiCreatePetParams"},
+		createPet.RequestBody.Content["application/json"].Schema)
+	created := createPet.Responses["2XX"]
+	require.Equal(t, map[string]OpenAPIHeader{"Location": {Schema: &Schema{Type: "string"}}}, created.Headers)
+	require.Equal(t, &Schema{Ref: "#/components/schemas/apiCreatedPet"}, created.Content["application/json"].Schema)
+
+	events := doc.Paths["/pets/{name}/events/{rest}"]["get"]
+	require.Empty(t, events.OperationID)
+	require.Len(t, events.Parameters, 2)
+	require.Equal(t, "rest", events.Parameters[1].Name)
+	require.Contains(t, events.Responses["200"].Content, "text/event-stream")
+	require.Contains(t, events.Responses["200"].Content, "application/x-ndjson")
+
+	schemas := doc.Components.Schemas
+	require.Equal(t, &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"name":   {Type: "string"},
+			"born":   {Type: "string", Format: "date-time"},
+			"tags":   {Type: "array", Items: &Schema{Type: "string"}},
+			"parent": {Ref: "#/components/schemas/apiPet"},
+		},
+		Required: []string{"name", "born"},
+	}, schemas["apiPet"])
+	require.Equal(t, schemas["apiPet"].Properties, schemas["apiCreatePetParams"].Properties)
+	require.Equal(t, map[string]*Schema{
+		"id":      {Type: "integer", Format: "int64"},
+		"litter":  {Type: "integer", Format: "int64"},
+		"version": {Type: "integer", Format: "int32"},
+	}, schemas["apiCreatedPet"].Properties)
+	require.Equal(t, OpenAPISecurityScheme{Type: "http", Scheme: "bearer"}, doc.Components.SecuritySchemes["bearerAuth"])
+
+	rw := httptest.NewRecorder()
+	doc.ServeHTTP(rw, httptest.NewRequest("GET", "/openapi.json", nil))
+	require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+	require.Equal(t, "application/json", rw.Result().Header.Get("Content-Type"))
+	served := map[string]any{}
+	require.NoError(t, json.NewDecoder(rw.Body).Decode(&served))
+	require.Equal(t, "3.1.0", served["openapi"])
+}
@@ -0,0 +1,207 @@
+package httpwrap
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+// The stable codes set in the `code` extension member of the Problem
+// Details produced by ProblemFromError.
+const (
+	// ProblemCodeDecode is the code of the errors returned by the Decoder.
+	ProblemCodeDecode = "decode_error"
+
+	// ProblemCodeInternal is the code of the errors that do not carry any
+	// HTTP information.
+	ProblemCodeInternal = "internal_error"
+)
+
+// ProblemError implements HTTPError and is sent to the client as an
+// RFC 9457 Problem Details object with the application/problem+json
+// media type.
+type ProblemError struct {
+	// Type is a URI reference that identifies the problem type. When
+	// empty, the problem type is "about:blank".
+	Type string
+
+	// Title is a short summary of the problem type.
+	Title string
+
+	// Status is the HTTP status code of the response.
+	Status int
+
+	// Detail is an explanation specific to this occurrence of the problem.
+	Detail string
+
+	// Instance is a URI reference that identifies this occurrence of the
+	// problem.
+	Instance string
+
+	// Extensions holds additional members of the problem object. They
+	// cannot override the members above.
+	Extensions map[string]any
+
+	// headers holds the headers carried over from the error that the
+	// problem was created from.
+	headers http.Header
+}
+
+var _ HTTPError = (*ProblemError)(nil)
+
+// NewProblemError returns a ProblemError with the given status, whose title
+// is the text of that status and detail is formatted from the arguments.
+func NewProblemError(status int, format string, args ...any) *ProblemError {
+	return &ProblemError{
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: fmt.Sprintf(format, args...),
+	}
+}
+
+// With sets an extension member of the problem and returns the problem.
+func (p *ProblemError) With(key string, value any) *ProblemError {
+	if p.Extensions == nil {
+		p.Extensions = map[string]any{}
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+func (p *ProblemError) Error() string {
+	return fmt.Sprintf("problem: %d: %s: %s", p.Status, p.Title, p.Detail)
+}
+
+func (p *ProblemError) StatusCode() int { return p.Status }
+
+func (p *ProblemError) Headers() http.Header {
+	headers := p.headers.Clone()
+	if headers == nil {
+		headers = http.Header{}
+	}
+	headers.Set("Content-Type", "application/problem+json")
+	return headers
+}
+
+func (p *ProblemError) WriteBody(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(p)
+}
+
+// MarshalJSON implements json.Marshaler, flattening the extension members
+// into the problem object.
+func (p *ProblemError) MarshalJSON() ([]byte, error) {
+	members := make(map[string]any, len(p.Extensions)+5)
+	maps.Copy(members, p.Extensions)
+	for key, val := range map[string]string{
+		"type":     p.Type,
+		"title":    p.Title,
+		"detail":   p.Detail,
+		"instance": p.Instance,
+	} {
+		delete(members, key)
+		if val != "" {
+			members[key] = val
+		}
+	}
+	delete(members, "status")
+	if p.Status != 0 {
+		members["status"] = p.Status
+	}
+	return json.Marshal(members)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, reading the members that are
+// not standard into the extensions of the problem.
+func (p *ProblemError) UnmarshalJSON(data []byte) error {
+	members := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+
+	*p = ProblemError{}
+	for key, target := range map[string]any{
+		"type":     &p.Type,
+		"title":    &p.Title,
+		"status":   &p.Status,
+		"detail":   &p.Detail,
+		"instance": &p.Instance,
+	} {
+		if raw, found := members[key]; found {
+			if err := json.Unmarshal(raw, target); err != nil {
+				return fmt.Errorf("invalid problem member %q: %w", key, err)
+			}
+			delete(members, key)
+		}
+	}
+
+	for key, raw := range members {
+		var val any
+		if err := json.Unmarshal(raw, &val); err != nil {
+			return err
+		}
+		p.With(key, val)
+	}
+	return nil
+}
+
+// ProblemFromError converts any error into a ProblemError carrying a stable
+// `code` extension member:
+//
+// - ProblemErrors are returned as they are.
+//
+// - DecodeErrors become a 400 with the ProblemCodeDecode code.
+//
+// - Errors wrapping an HTTPResponse keep their status code, their body becomes the detail
+// of the problem and their code is derived from the status text (e.g:
+// not_found). Their headers are kept.
+//
+// - Any other error becomes a 500 with the ProblemCodeInternal code. The
+// error string is not sent to the client.
+func ProblemFromError(err error) *ProblemError {
+	var problem *ProblemError
+	var decodeErr *DecodeError
+	if errors.As(err, &problem) {
+		return problem
+	} else if errors.As(err, &decodeErr) {
+		return NewProblemError(http.StatusBadRequest, "%s", decodeErr.Error()).
+			With("code", ProblemCodeDecode)
+	}
+
+	var cast HTTPResponse
+	if errors.As(err, &cast) {
+		buf := &bytes.Buffer{}
+		_ = cast.WriteBody(buf)
+		status := cast.StatusCode()
+		problem = NewProblemError(status, "%s", strings.TrimSpace(buf.String())).
+			With("code", statusCode(status))
+		if headers, ok := cast.(HTTPHeaders); ok {
+			problem.headers = headers.Headers()
+		}
+		return problem
+	}
+
+	return &ProblemError{
+		Title:      http.StatusText(http.StatusInternalServerError),
+		Status:     http.StatusInternalServerError,
+		Extensions: map[string]any{"code": ProblemCodeInternal},
+	}
+}
+
+// statusCode returns the snake cased text of the status, e.g: not_found.
+func statusCode(status int) string {
+	words := strings.FieldsFunc(strings.ToLower(http.StatusText(status)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return fmt.Sprintf("status_%d", status)
+	}
+	return strings.Join(words, "_")
+}
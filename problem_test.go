@@ -0,0 +1,112 @@
+package httpwrap
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblemError(t *testing.T) {
+	t.Run("as http error", func(t *testing.T) {
+		handler := NewStandardWrapper().Wrap(func() error {
+			problem := NewProblemError(http.StatusNotFound, "pet %s does not exist", "rex").
+				With("code", "pet_not_found")
+			problem.Type = "https://example.com/problems/pet-not-found"
+			problem.Instance = "/pets/rex"
+			return problem
+		})
+
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, httptest.NewRequest("GET", "/pets/rex", nil))
+		statusCode, body := readResponseRecorder(t, rw)
+		require.Equal(t, http.StatusNotFound, statusCode)
+		require.Equal(t, "application/problem+json", rw.Result().Header.Get("Content-Type"))
+		require.JSONEq(t, `{
+			"type": "https://example.com/problems/pet-not-found",
+			"title": "Not Found",
+			"status": 404,
+			"detail": "pet rex does not exist",
+			"instance": "/pets/rex",
+			"code": "pet_not_found"
+		}`, body)
+	})
+
+	t.Run("extensions cannot override members", func(t *testing.T) {
+		problem := NewProblemError(http.StatusConflict, "conflict").
+			With("status", 200).
+			With("title", "other")
+		encoded, err := json.Marshal(problem)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"title":"Conflict","status":409,"detail":"conflict"}`, string(encoded))
+	})
+}
+
+func TestProblemDetailsWriter(t *testing.T) {
+	type params struct {
+		Limit int `http:"query=limit"`
+	}
+	type bearerParams struct {
+		Token string `http:"bearer"`
+	}
+
+	wrapper := NewStandardWrapper().Finally(StandardResponseWriter(WithProblemDetails()))
+	cases := []struct {
+		name     string
+		url      string
+		header   string
+		handler  any
+		status   int
+		expected string
+	}{
+		{
+			name:     "decode error",
+			url:      "/pets?limit=abc",
+			handler:  func(params) error { return nil },
+			status:   http.StatusBadRequest,
+			expected: `"code":"decode_error"`,
+		},
+		{
+			name:     "unknown error",
+			url:      "/pets",
+			handler:  func() error { return fmt.Errorf("database password is hunter2") },
+			status:   http.StatusInternalServerError,
+			expected: `{"code":"internal_error","status":500,"title":"Internal Server Error"}`,
+		},
+		{
+			name:     "http error",
+			url:      "/pets",
+			handler:  func() error { return NewHTTPError(http.StatusForbidden, "Forbidden.") },
+			status:   http.StatusForbidden,
+			expected: `{"code":"forbidden","detail":"Forbidden.","status":403,"title":"Forbidden"}`,
+		},
+		{
+			name:     "keeps headers",
+			url:      "/pets",
+			header:   "WWW-Authenticate",
+			handler:  func(bearerParams) error { return nil },
+			status:   http.StatusUnauthorized,
+			expected: `"code":"unauthorized"`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", tc.url, nil)
+			req.Header.Set("Authorization", "Basic abc")
+			wrapper.Wrap(tc.handler).ServeHTTP(rw, req)
+
+			statusCode, body := readResponseRecorder(t, rw)
+			require.Equal(t, tc.status, statusCode)
+			require.Equal(t, "application/problem+json", rw.Result().Header.Get("Content-Type"))
+			require.Contains(t, body, tc.expected)
+			if tc.header != "" {
+				require.NotEmpty(t, rw.Result().Header.Get(tc.header))
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package httpwrap
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ResponseInfo gives access to what has been sent to the client so far. It
+// is injected into every function of the wrapper, which makes it most useful
+// for finalizers that log or measure the response once it has been written.
+type ResponseInfo struct {
+	rec *responseRecorder
+}
+
+// Status returns the status code sent to the client, or `0` if the headers
+// have not been sent yet.
+func (info ResponseInfo) Status() int {
+	if info.rec == nil {
+		return 0
+	}
+	return info.rec.status
+}
+
+// BytesWritten returns the number of bytes of the body sent to the client.
+func (info ResponseInfo) BytesWritten() int64 {
+	if info.rec == nil {
+		return 0
+	}
+	return info.rec.bytes
+}
+
+// TimeToFirstByte returns the time between the start of the request and the
+// moment the headers were sent, or `0` if they have not been sent yet.
+func (info ResponseInfo) TimeToFirstByte() time.Duration {
+	if info.rec == nil {
+		return 0
+	}
+	return info.rec.firstByte
+}
+
+// HeadersSent returns whether the headers of the response have been sent.
+func (info ResponseInfo) HeadersSent() bool {
+	return info.rec != nil && info.rec.wroteHeader
+}
+
+// responseRecorder is the http.ResponseWriter given to the functions of the
+// wrapper. It records the status and size of the response while passing
+// through to the original writer, including its http.Flusher,
+// http.Hijacker, http.Pusher and io.ReaderFrom implementations, which
+// return http.ErrNotSupported when the original writer has none. The other
+// optional methods of the original writer, e.g: SetWriteDeadline or
+// EnableFullDuplex, are reached with an http.ResponseController.
+type responseRecorder struct {
+	http.ResponseWriter
+	start time.Time
+
+	status      int
+	bytes       int64
+	firstByte   time.Duration
+	wroteHeader bool
+}
+
+func newResponseRecorder(rw http.ResponseWriter) *responseRecorder {
+	return &responseRecorder{
+		ResponseWriter: rw,
+		start:          time.Now(),
+	}
+}
+
+func (rec *responseRecorder) WriteHeader(code int) {
+	if !rec.wroteHeader && code >= 200 {
+		rec.wroteHeader = true
+		rec.status = code
+		rec.firstByte = time.Since(rec.start)
+	}
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+func (rec *responseRecorder) Write(p []byte) (int, error) {
+	if !rec.wroteHeader {
+		rec.WriteHeader(http.StatusOK)
+	}
+	n, err := rec.ResponseWriter.Write(p)
+	rec.bytes += int64(n)
+	return n, err
+}
+
+func (rec *responseRecorder) ReadFrom(r io.Reader) (int64, error) {
+	if !rec.wroteHeader {
+		rec.WriteHeader(http.StatusOK)
+	}
+
+	var n int64
+	var err error
+	if readerFrom, ok := rec.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = readerFrom.ReadFrom(r)
+	} else {
+		n, err = io.Copy(writerOnly{rec.ResponseWriter}, r)
+	}
+	rec.bytes += n
+	return n, err
+}
+
+func (rec *responseRecorder) Flush() {
+	if !rec.wroteHeader {
+		rec.WriteHeader(http.StatusOK)
+	}
+	_ = http.NewResponseController(rec.ResponseWriter).Flush()
+}
+
+func (rec *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(rec.ResponseWriter).Hijack()
+}
+
+func (rec *responseRecorder) Push(target string, opts *http.PushOptions) error {
+	for rw := rec.ResponseWriter; rw != nil; {
+		if pusher, ok := rw.(http.Pusher); ok {
+			return pusher.Push(target, opts)
+		}
+		unwrapper, ok := rw.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			break
+		}
+		rw = unwrapper.Unwrap()
+	}
+	return http.ErrNotSupported
+}
+
+// Unwrap lets http.ResponseController reach the original writer.
+func (rec *responseRecorder) Unwrap() http.ResponseWriter { return rec.ResponseWriter }
+
+// writerOnly hides the io.ReaderFrom implementation of a writer so that
+// io.Copy does not loop back into it.
+type writerOnly struct {
+	io.Writer
+}
+
+// discardWriter is the http.ResponseWriter of the responses that have no
+// client to be sent to, e.g: the responses written by Invoke.
+type discardWriter struct {
+	header http.Header
+}
+
+func (w *discardWriter) Header() http.Header { return w.header }
+
+func (w *discardWriter) Write(p []byte) (int, error) { return len(p), nil }
+
+func (w *discardWriter) WriteHeader(int) {}
@@ -0,0 +1,126 @@
+package httpwrap
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseInfo(t *testing.T) {
+	t.Run("finalizer sees the response", func(t *testing.T) {
+		writer := StandardResponseWriter()
+		var info ResponseInfo
+		handler := New().
+			Finally(func(w http.ResponseWriter, req *http.Request, res any, err error, i ResponseInfo) {
+				require.False(t, i.HeadersSent())
+				writer(w, req, res, err)
+				info = i
+			}).
+			Wrap(func() error {
+				return NewHTTPError(http.StatusTeapot, "short and stout")
+			})
+
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, httptest.NewRequest("GET", "/tea", nil))
+		require.True(t, info.HeadersSent())
+		require.Equal(t, http.StatusTeapot, info.Status())
+		require.Equal(t, int64(len("short and stout")), info.BytesWritten())
+		require.True(t, info.TimeToFirstByte() > 0)
+	})
+
+	t.Run("implicit status", func(t *testing.T) {
+		var info ResponseInfo
+		handler := New().
+			Finally(func(i ResponseInfo) { info = i }).
+			Wrap(func(w http.ResponseWriter) {
+				_, err := io.Copy(w, strings.NewReader("hello"))
+				require.NoError(t, err)
+				w.(http.Flusher).Flush()
+			})
+
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, httptest.NewRequest("GET", "/hello", nil))
+		require.Equal(t, http.StatusOK, info.Status())
+		require.Equal(t, int64(5), info.BytesWritten())
+		require.True(t, rw.Flushed)
+	})
+
+	t.Run("nothing written", func(t *testing.T) {
+		var info ResponseInfo
+		handler := New().
+			Finally(func(i ResponseInfo) { info = i }).
+			Wrap(func() {})
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		require.False(t, info.HeadersSent())
+		require.Equal(t, 0, info.Status())
+	})
+
+	t.Run("hijack", func(t *testing.T) {
+		handler := New().Wrap(func(w http.ResponseWriter) {
+			conn, buf, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			defer conn.Close()
+			_, err = buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\n\r\nhijacked")
+			require.NoError(t, err)
+			require.NoError(t, buf.Flush())
+		})
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		res, err := http.Get(server.URL)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body, err := io.ReadAll(bufio.NewReader(res.Body))
+		require.NoError(t, err)
+		require.Equal(t, "hijacked", string(body))
+	})
+	t.Run("push", func(t *testing.T) {
+		var err error
+		handler := New().Wrap(func(w http.ResponseWriter) {
+			err = w.(http.Pusher).Push("/style.css", nil)
+		})
+
+		rw := &pushRecorder{ResponseRecorder: httptest.NewRecorder()}
+		handler.ServeHTTP(rw, httptest.NewRequest("GET", "/", nil))
+		require.NoError(t, err)
+		require.Equal(t, []string{"/style.css"}, rw.pushed)
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		require.True(t, errors.Is(err, http.ErrNotSupported))
+	})
+
+	t.Run("response controller", func(t *testing.T) {
+		handler := New().Wrap(func(w http.ResponseWriter) {
+			require.NoError(t, http.NewResponseController(w).SetWriteDeadline(time.Now().Add(time.Minute)))
+			_, _ = w.Write([]byte("ok"))
+		})
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		res, err := http.Get(server.URL)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.Equal(t, "ok", string(body))
+	})
+}
+
+// pushRecorder is a ResponseRecorder that supports HTTP/2 server pushes.
+type pushRecorder struct {
+	*httptest.ResponseRecorder
+	pushed []string
+}
+
+func (rw *pushRecorder) Push(target string, _ *http.PushOptions) error {
+	rw.pushed = append(rw.pushed, target)
+	return nil
+}
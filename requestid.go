@@ -0,0 +1,144 @@
+package httpwrap
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// RequestIDHeader is the header that carries the ID of a request, both in
+// the requests and in their responses.
+const RequestIDHeader = "X-Request-ID"
+
+// _maxRequestIDLength is the maximum length of the request IDs accepted
+// from clients. Longer IDs are replaced by a generated one.
+const _maxRequestIDLength = 128
+
+// RequestID is the ID of a request, used to correlate the responses sent
+// to clients with the logs of the server. It is provided by the
+// ReadRequestID before:
+//
+//	wrapper := httpwrap.NewStandardWrapper().Before(httpwrap.ReadRequestID)
+//
+//	func GetPet(id httpwrap.RequestID, params GetPetParams) (Pet, error) {
+//		...
+//	}
+type RequestID string
+
+type requestIDKey struct{}
+
+// ReadRequestID reads the ID of the request from its X-Request-ID header,
+// or from the trace ID of its W3C traceparent header, and generates one when
+// neither is usable. The ID is sent back in the X-Request-ID header of the
+// response and attached to the context of the request, which is provided to
+// the next stages in place of the original request.
+func ReadRequestID(w http.ResponseWriter, req *http.Request) (RequestID, *http.Request) {
+	id := RequestID(req.Header.Get(RequestIDHeader))
+	if !validRequestID(string(id)) {
+		id = traceID(req.Header.Get("traceparent"))
+	}
+	if id == "" {
+		id = newRequestID()
+	}
+
+	w.Header().Set(RequestIDHeader, string(id))
+	return id, req.WithContext(ContextWithRequestID(req.Context(), id))
+}
+
+// ContextWithRequestID returns a copy of the context carrying the request ID.
+func ContextWithRequestID(ctx context.Context, id RequestID) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by the context, if
+// any.
+func RequestIDFromContext(ctx context.Context) (RequestID, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(RequestID)
+	return id, ok && id != ""
+}
+
+// RequestIDHandler decorates the slog.Handler so that the records logged
+// with a context carrying a request ID have a request_id attribute.
+//
+//	logger := slog.New(httpwrap.RequestIDHandler(slog.NewJSONHandler(os.Stdout, nil)))
+//	logger.InfoContext(req.Context(), "pet adopted")
+func RequestIDHandler(next slog.Handler) slog.Handler {
+	return requestIDHandler{Handler: next}
+}
+
+type requestIDHandler struct {
+	slog.Handler
+}
+
+func (h requestIDHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id, ok := RequestIDFromContext(ctx); ok && !hasAttr(record, "request_id") {
+		record = record.Clone()
+		record.AddAttrs(slog.String("request_id", string(id)))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h requestIDHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return requestIDHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h requestIDHandler) WithGroup(name string) slog.Handler {
+	return requestIDHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// hasAttr returns whether the record has a top level attribute with the key.
+func hasAttr(record slog.Record, key string) bool {
+	found := false
+	record.Attrs(func(attr slog.Attr) bool {
+		found = attr.Key == key
+		return !found
+	})
+	return found
+}
+
+// validRequestID returns whether the ID sent by a client can be used as is.
+// Only short IDs made of visible ASCII characters are accepted, so that
+// they can safely be echoed and logged.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > _maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// traceID returns the trace ID of a traceparent header, or an empty string
+// if the header is malformed. See https://www.w3.org/TR/trace-context/.
+func traceID(traceparent string) RequestID {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 {
+		return ""
+	}
+	if !isLowerHex(parts[0]) || !isLowerHex(parts[1]) || strings.Trim(parts[1], "0") == "" {
+		return ""
+	}
+	return RequestID(parts[1])
+}
+
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if (s[i] < '0' || s[i] > '9') && (s[i] < 'a' || s[i] > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID generates a random request ID, formatted like a trace ID.
+func newRequestID() RequestID {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return RequestID(hex.EncodeToString(buf))
+}
@@ -0,0 +1,122 @@
+package httpwrap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadRequestID(t *testing.T) {
+	var seen RequestID
+	var fromContext RequestID
+	handler := NewStandardWrapper().
+		Before(ReadRequestID).
+		Wrap(func(id RequestID, req *http.Request) {
+			seen = id
+			fromContext, _ = RequestIDFromContext(req.Context())
+		})
+
+	serve := func(headers map[string]string) *httptest.ResponseRecorder {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/endpoint", nil)
+		for key, val := range headers {
+			req.Header.Set(key, val)
+		}
+		handler.ServeHTTP(rw, req)
+		return rw
+	}
+
+	t.Run("header", func(t *testing.T) {
+		rw := serve(map[string]string{"X-Request-ID": "abc-123"})
+		require.Equal(t, RequestID("abc-123"), seen)
+		require.Equal(t, seen, fromContext)
+		require.Equal(t, "abc-123", rw.Result().Header.Get("X-Request-ID"))
+	})
+
+	t.Run("traceparent", func(t *testing.T) {
+		rw := serve(map[string]string{
+			"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		})
+		require.Equal(t, RequestID("4bf92f3577b34da6a3ce929d0e0e4736"), seen)
+		require.Equal(t, seen, fromContext)
+		require.Equal(t, string(seen), rw.Result().Header.Get("X-Request-ID"))
+	})
+
+	t.Run("generated", func(t *testing.T) {
+		rw := serve(map[string]string{
+			"X-Request-ID": "bad\nid",
+			"traceparent":  "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		})
+		require.Regexp(t, `^[0-9a-f]{32}$`, string(seen))
+		require.Equal(t, seen, fromContext)
+		require.Equal(t, string(seen), rw.Result().Header.Get("X-Request-ID"))
+
+		previous := seen
+		serve(nil)
+		require.NotEqual(t, previous, seen)
+	})
+}
+
+func TestRequestIDErrors(t *testing.T) {
+	fail := func() error { return fmt.Errorf("failed") }
+
+	t.Run("plain", func(t *testing.T) {
+		handler := NewStandardWrapper().Before(ReadRequestID).Wrap(fail)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/endpoint", nil)
+		req.Header.Set("X-Request-ID", "abc-123")
+		handler.ServeHTTP(rw, req)
+		require.Equal(t, http.StatusInternalServerError, rw.Result().StatusCode)
+		require.Equal(t, "abc-123", rw.Result().Header.Get("X-Request-ID"))
+	})
+
+	t.Run("problem details", func(t *testing.T) {
+		problem := NewProblemError(http.StatusConflict, "Pet already adopted.")
+		handler := NewStandardWrapper().
+			Before(ReadRequestID).
+			Finally(StandardResponseWriter(WithProblemDetails())).
+			Wrap(func() error { return problem })
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/endpoint", nil)
+		req.Header.Set("X-Request-ID", "abc-123")
+		handler.ServeHTTP(rw, req)
+		require.Equal(t, http.StatusConflict, rw.Result().StatusCode)
+
+		body := map[string]any{}
+		require.NoError(t, json.NewDecoder(rw.Body).Decode(&body))
+		require.Equal(t, "abc-123", body["request_id"])
+		require.Empty(t, problem.Extensions, "the returned problem must not be modified")
+	})
+}
+
+func TestRequestIDHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(RequestIDHandler(slog.NewJSONHandler(buf, nil))).With("service", "pets")
+	ctx := ContextWithRequestID(context.Background(), "abc-123")
+
+	logger.InfoContext(ctx, "adopted")
+	logger.InfoContext(ctx, "explicit", "request_id", "other")
+	logger.InfoContext(context.Background(), "anonymous")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+
+	records := make([]map[string]any, len(lines))
+	for i, line := range lines {
+		require.NoError(t, json.Unmarshal([]byte(line), &records[i]))
+		require.Equal(t, "pets", records[i]["service"])
+	}
+	require.Equal(t, "abc-123", records[0]["request_id"])
+	require.Equal(t, "other", records[1]["request_id"])
+	require.NotContains(t, records[2], "request_id")
+}
@@ -3,18 +3,26 @@ package httpwrap
 import (
 	"encoding/json"
 	"io"
+	"net/http"
 )
 
 // HTTPResponse is used by the StandardResponseWriter to construct the
 // response body according to the StatusCode() and WriteBody() functions.
 // If the StatusCode() function returns `0`, the StandardResponseWriter will
 // assume that WriteHeader has already been called on the http.ResponseWriter
-// object.
+// object. Responses that need to set headers can also implement HTTPHeaders.
 type HTTPResponse interface {
 	StatusCode() int
 	WriteBody(io.Writer) error
 }
 
+// HTTPHeaders can optionally be implemented by an HTTPResponse. The
+// StandardResponseWriter sets these headers on the response before
+// writing the status code.
+type HTTPHeaders interface {
+	Headers() http.Header
+}
+
 // The jsonResponse type implements HTTPResponse. When returned, it will
 // write the status code in the http response's header and JSON encode the
 // body.
@@ -37,3 +45,66 @@ func (res jsonResponse) WriteBody(writer io.Writer) error {
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(res.body)
 }
+
+// The headerResponse type implements HTTPResponse and HTTPHeaders. When
+// returned, it will set its headers and status code, and JSON encode the
+// body if it has one.
+type headerResponse struct {
+	code    int
+	headers http.Header
+	body    any
+	hasBody bool
+}
+
+func (res headerResponse) StatusCode() int { return res.code }
+
+func (res headerResponse) Headers() http.Header { return res.headers }
+
+func (res headerResponse) WriteBody(writer io.Writer) error {
+	if !res.hasBody {
+		return nil
+	}
+	return jsonResponse{code: res.code, body: res.body}.WriteBody(writer)
+}
+
+// Created returns a 201 Created response pointing to the new resource with
+// its Location header, and JSON encoding the body.
+func Created[T any](location string, body T) HTTPResponse {
+	return headerResponse{
+		code: http.StatusCreated,
+		headers: http.Header{
+			"Location":     []string{location},
+			"Content-Type": []string{"application/json"},
+		},
+		body:    body,
+		hasBody: true,
+	}
+}
+
+// Accepted returns a 202 Accepted response whose Location header points to
+// where the status of the request can be monitored.
+func Accepted(statusURL string) HTTPResponse {
+	return headerResponse{
+		code:    http.StatusAccepted,
+		headers: http.Header{"Location": []string{statusURL}},
+	}
+}
+
+// NoContent returns a 204 No Content response.
+func NoContent() HTTPResponse {
+	return headerResponse{code: http.StatusNoContent}
+}
+
+// NotModified returns a 304 Not Modified response.
+func NotModified() HTTPResponse {
+	return headerResponse{code: http.StatusNotModified}
+}
+
+// Redirect returns a response that redirects the client to the URL with the
+// given 3xx status code.
+func Redirect(code int, url string) HTTPResponse {
+	return headerResponse{
+		code:    code,
+		headers: http.Header{"Location": []string{url}},
+	}
+}
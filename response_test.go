@@ -0,0 +1,53 @@
+package httpwrap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusResponses(t *testing.T) {
+	serve := func(res HTTPResponse) *httptest.ResponseRecorder {
+		rw := httptest.NewRecorder()
+		handler := NewStandardWrapper().Wrap(func() HTTPResponse { return res })
+		handler.ServeHTTP(rw, httptest.NewRequest("POST", "/pets", nil))
+		return rw
+	}
+
+	t.Run("created", func(t *testing.T) {
+		rw := serve(Created("/pets/rex", typedResponse{Value: 1}))
+		statusCode, body := readResponseRecorder(t, rw)
+		require.Equal(t, http.StatusCreated, statusCode)
+		require.JSONEq(t, `{"value":1}`, body)
+		require.Equal(t, "/pets/rex", rw.Result().Header.Get("Location"))
+		require.Equal(t, "application/json", rw.Result().Header.Get("Content-Type"))
+	})
+
+	t.Run("accepted", func(t *testing.T) {
+		rw := serve(Accepted("/jobs/1"))
+		statusCode, body := readResponseRecorder(t, rw)
+		require.Equal(t, http.StatusAccepted, statusCode)
+		require.Empty(t, body)
+		require.Equal(t, "/jobs/1", rw.Result().Header.Get("Location"))
+	})
+
+	t.Run("no content", func(t *testing.T) {
+		rw := serve(NoContent())
+		statusCode, body := readResponseRecorder(t, rw)
+		require.Equal(t, http.StatusNoContent, statusCode)
+		require.Empty(t, body)
+	})
+
+	t.Run("not modified", func(t *testing.T) {
+		rw := serve(NotModified())
+		require.Equal(t, http.StatusNotModified, rw.Result().StatusCode)
+	})
+
+	t.Run("redirect", func(t *testing.T) {
+		rw := serve(Redirect(http.StatusSeeOther, "/pets"))
+		require.Equal(t, http.StatusSeeOther, rw.Result().StatusCode)
+		require.Equal(t, "/pets", rw.Result().Header.Get("Location"))
+	})
+}
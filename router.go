@@ -0,0 +1,130 @@
+package httpwrap
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Route is an endpoint registered on a Router.
+type Route struct {
+	// Method is the HTTP method of the route. An empty method matches
+	// every method.
+	Method string
+
+	// Path is the path pattern of the route, as understood by
+	// http.ServeMux (e.g: /pets/{name}).
+	Path string
+
+	// Handler is the main function of the route, as given to Wrap.
+	Handler any
+}
+
+// Router registers wrapped handlers on an http.ServeMux, and records every
+// registration so that the routes can be introspected, e.g: to generate an
+// OpenAPI document.
+//
+// Groups of routes share a path prefix and a wrapper, which inherits the
+// befores of the parent router and can be extended with Use:
+//
+//	router := httpwrap.NewRouter(httpwrap.NewStandardWrapper())
+//	router.GET("/health", Health)
+//	router.Group("/pets", func(g *httpwrap.Router) {
+//		g.Use(checkAPICreds)
+//		g.GET("/{name}", GetPetByName)
+//		g.Group("/admin", func(g *httpwrap.Router) {
+//			g.Use(checkAdmin)
+//			g.POST("/clear", ClearStore)
+//		})
+//	})
+//	http.ListenAndServe(":3000", router)
+type Router struct {
+	mux     *http.ServeMux
+	wrapper Wrapper
+	prefix  string
+	routes  *[]registration
+}
+
+// registration is a route along with the wrapper its handler was wrapped
+// with.
+type registration struct {
+	Route
+	wrapper Wrapper
+}
+
+// NewRouter returns a new Router that wraps its handlers with the wrapper.
+func NewRouter(wrapper Wrapper) *Router {
+	return &Router{
+		mux:     http.NewServeMux(),
+		wrapper: wrapper,
+		routes:  &[]registration{},
+	}
+}
+
+// Group calls fn with a router whose routes are registered under the
+// prefix, and whose wrapper is a copy of the wrapper of this router.
+func (r *Router) Group(prefix string, fn func(g *Router)) {
+	fn(&Router{
+		mux:     r.mux,
+		wrapper: r.wrapper,
+		prefix:  r.prefix + strings.TrimSuffix(prefix, "/"),
+		routes:  r.routes,
+	})
+}
+
+// Use adds befores to the wrapper of the router. They apply to the routes
+// registered on the router afterwards, including the ones of its groups.
+func (r *Router) Use(befores ...any) {
+	r.wrapper = r.wrapper.Before(befores...)
+}
+
+// GET registers the main function for GET requests on the path.
+func (r *Router) GET(path string, fn any) { r.Handle(http.MethodGet, path, fn) }
+
+// HEAD registers the main function for HEAD requests on the path.
+func (r *Router) HEAD(path string, fn any) { r.Handle(http.MethodHead, path, fn) }
+
+// POST registers the main function for POST requests on the path.
+func (r *Router) POST(path string, fn any) { r.Handle(http.MethodPost, path, fn) }
+
+// PUT registers the main function for PUT requests on the path.
+func (r *Router) PUT(path string, fn any) { r.Handle(http.MethodPut, path, fn) }
+
+// PATCH registers the main function for PATCH requests on the path.
+func (r *Router) PATCH(path string, fn any) { r.Handle(http.MethodPatch, path, fn) }
+
+// DELETE registers the main function for DELETE requests on the path.
+func (r *Router) DELETE(path string, fn any) { r.Handle(http.MethodDelete, path, fn) }
+
+// OPTIONS registers the main function for OPTIONS requests on the path.
+func (r *Router) OPTIONS(path string, fn any) { r.Handle(http.MethodOptions, path, fn) }
+
+// Handle wraps the main function and registers it for the method and path,
+// prefixed with the prefix of the group. Like http.ServeMux, it panics if the
+// pattern conflicts with a registered one.
+func (r *Router) Handle(method, path string, fn any) {
+	path = r.prefix + path
+	pattern := path
+	if method != "" {
+		pattern = strings.ToUpper(method) + " " + path
+	}
+	r.mux.Handle(pattern, r.wrapper.Wrap(fn))
+	*r.routes = append(*r.routes, registration{
+		Route:   Route{Method: strings.ToUpper(method), Path: path, Handler: fn},
+		wrapper: r.wrapper,
+	})
+}
+
+// Routes returns the routes registered on the router, in the order they
+// were registered.
+func (r *Router) Routes() []Route {
+	routes := make([]Route, len(*r.routes))
+	for i, reg := range *r.routes {
+		routes[i] = reg.Route
+	}
+	return routes
+}
+
+// ServeHTTP implements `http.Handler`.
+func (r *Router) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	r.mux.ServeHTTP(rw, req)
+}
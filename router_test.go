@@ -0,0 +1,119 @@
+package httpwrap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type petParams struct {
+	Name string `http:"segment=name"`
+}
+
+func TestRouter(t *testing.T) {
+	getPet := func(params petParams) typedResponse {
+		return typedResponse{Value: len(params.Name)}
+	}
+	listPets := func() []string { return []string{"rex"} }
+
+	router := NewRouter(NewStandardWrapper())
+	router.Handle("get", "/pets/{name}", getPet)
+	router.Handle("GET", "/pets", listPets)
+
+	routes := router.Routes()
+	require.Len(t, routes, 2)
+	require.Equal(t, "GET", routes[0].Method)
+	require.Equal(t, "/pets/{name}", routes[0].Path)
+	require.Equal(t, "/pets", routes[1].Path)
+
+	rw := httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest("GET", "/pets/rex", nil))
+	statusCode, body := readResponseRecorder(t, rw)
+	require.Equal(t, http.StatusOK, statusCode)
+	require.Equal(t, `{"value":3}`, body)
+
+	rw = httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest("POST", "/pets/rex", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rw.Result().StatusCode)
+}
+
+func TestRouterGroups(t *testing.T) {
+	type role string
+	var calls []string
+	authenticate := func(req *http.Request) (role, error) {
+		calls = append(calls, "authenticate")
+		if req.Header.Get("Authorization") == "" {
+			return "", NewHTTPError(http.StatusUnauthorized, "Unauthorized.")
+		}
+		return role(req.Header.Get("Authorization")), nil
+	}
+	requireAdmin := func(r role) error {
+		calls = append(calls, "admin")
+		if r != "admin" {
+			return NewHTTPError(http.StatusForbidden, "Forbidden.")
+		}
+		return nil
+	}
+
+	router := NewRouter(NewStandardWrapper())
+	router.GET("/health", func() string { return "ok" })
+	router.Group("/api/", func(g *Router) {
+		g.Use(authenticate)
+		g.GET("/pets/{name}", func(params petParams) string { return params.Name })
+		g.Group("/admin", func(g *Router) {
+			g.Use(requireAdmin)
+			g.DELETE("/pets/{name}", func(r role, params petParams) string { return string(r) + " deleted " + params.Name })
+		})
+		g.POST("/pets", func() string { return "created" })
+	})
+
+	paths := []string{}
+	for _, route := range router.Routes() {
+		paths = append(paths, route.Method+" "+route.Path)
+	}
+	require.Equal(t, []string{
+		"GET /health",
+		"GET /api/pets/{name}",
+		"DELETE /api/admin/pets/{name}",
+		"POST /api/pets",
+	}, paths)
+
+	serve := func(method, path, authorization string) (int, string) {
+		calls = nil
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		router.ServeHTTP(rw, req)
+		return readResponseRecorder(t, rw)
+	}
+
+	statusCode, body := serve("GET", "/health", "")
+	require.Equal(t, http.StatusOK, statusCode)
+	require.Equal(t, `"ok"`, body)
+	require.Empty(t, calls)
+
+	statusCode, _ = serve("GET", "/api/pets/rex", "")
+	require.Equal(t, http.StatusUnauthorized, statusCode)
+
+	statusCode, body = serve("GET", "/api/pets/rex", "user")
+	require.Equal(t, http.StatusOK, statusCode)
+	require.Equal(t, `"rex"`, body)
+	require.Equal(t, []string{"authenticate"}, calls)
+
+	statusCode, _ = serve("DELETE", "/api/admin/pets/rex", "user")
+	require.Equal(t, http.StatusForbidden, statusCode)
+	require.Equal(t, []string{"authenticate", "admin"}, calls)
+
+	statusCode, body = serve("DELETE", "/api/admin/pets/rex", "admin")
+	require.Equal(t, http.StatusOK, statusCode)
+	require.Equal(t, `"admin deleted rex"`, body)
+
+	statusCode, body = serve("POST", "/api/pets", "user")
+	require.Equal(t, http.StatusOK, statusCode)
+	require.Equal(t, `"created"`, body)
+	require.Equal(t, []string{"authenticate"}, calls)
+}
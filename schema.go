@@ -0,0 +1,165 @@
+package httpwrap
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	_timeType          = reflect.TypeOf(time.Time{})
+	_textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Schema is a JSON Schema, as used by OpenAPI 3.1 documents.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+}
+
+// schemaGenerator generates the JSON Schemas of Go types by reflection,
+// following the rules of encoding/json. Named structs are generated once
+// as components and referenced from the other schemas.
+type schemaGenerator struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{
+		components: map[string]*Schema{},
+		names:      map[reflect.Type]string{},
+	}
+}
+
+// schema returns the schema of the values of the type once encoded as JSON.
+func (g *schemaGenerator) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == _timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Implements(_textMarshalerType):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String && !t.Key().Implements(_textMarshalerType) {
+			return &Schema{}
+		}
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + g.component(t)}
+	}
+	return &Schema{}
+}
+
+// component generates the schema of the named struct as a component if it
+// was not already, and returns the name of the component.
+func (g *schemaGenerator) component(t reflect.Type) string {
+	if name, found := g.names[t]; found {
+		return name
+	}
+
+	name := componentName(t.Name())
+	if _, taken := g.components[name]; taken {
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		base := componentName(pkg + "." + t.Name())
+		name = base
+		for i := 2; g.components[name] != nil; i++ {
+			name = fmt.Sprintf("%s_%d", base, i)
+		}
+	}
+
+	// The name is reserved before generating the schema so that recursive
+	// types reference themselves.
+	g.names[t] = name
+	g.components[name] = &Schema{}
+	*g.components[name] = *g.structSchema(t)
+	return name
+}
+
+// structSchema returns the inline schema of the struct. The fields with
+// `http` tags are left out, as they are not part of the JSON bodies.
+func (g *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, field := range bodyFields(t) {
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = g.schema(field.Type)
+		if field.Type.Kind() != reflect.Ptr && !strings.Contains(opts, "omitempty") && !strings.Contains(opts, "omitzero") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
+
+// bodyFields returns the fields of the struct that are part of its JSON
+// encoding and are not tagged with `http`. Embedded structs without a JSON
+// name have their fields promoted, as encoding/json does.
+func bodyFields(t reflect.Type) []reflect.StructField {
+	fields := []reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if jsonName == "-" || field.Tag.Get("http") != "" {
+			continue
+		}
+
+		embedded := field.Type
+		if embedded.Kind() == reflect.Ptr {
+			embedded = embedded.Elem()
+		}
+		if field.Anonymous && jsonName == "" && embedded.Kind() == reflect.Struct {
+			fields = append(fields, bodyFields(embedded)...)
+		} else if field.IsExported() {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// componentName replaces the characters that are not allowed in the names
+// of OpenAPI components, e.g: the brackets of generic types.
+func componentName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+}
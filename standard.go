@@ -1,9 +1,12 @@
 package httpwrap
 
 import (
-	"encoding/json"
-	"log"
+	"bytes"
+	"errors"
+	"log/slog"
+	"maps"
 	"net/http"
+	"strings"
 )
 
 // The StandardRequestReader decodes the request using the following:
@@ -18,69 +21,285 @@ import (
 //
 // - JSON Decoding of the http request body
 func StandardRequestReader() RequestReader {
-	decoder := NewDecoder()
-	return func(_ http.ResponseWriter, req *http.Request, obj any) error {
-		return decoder.Decode(req, obj)
-	}
+	return NewDecoder().RequestReader()
 }
 
 // StandardResponseWriter will try to cast the error and response objects to the
 // HTTPResponse interface and use them to send the response to the client.
 // By default, it will send a 200 OK and encode the response object as JSON.
 // If the HTTPResponse has a `0` StatusCode, WriteHeader will not be called.
-// If the error is not an HTTPResponse, a 500 status code will be returned with
-// the body being exactly the error's string.
-func StandardResponseWriter() ResponseWriter {
-	return func(w http.ResponseWriter, _ *http.Request, res any, err error) {
-		if err != nil {
-			if cast, ok := err.(HTTPResponse); ok {
-				code := cast.StatusCode()
-				if code != 0 {
-					w.WriteHeader(cast.StatusCode())
-				}
-				if sendError := cast.WriteBody(w); sendError != nil {
-					log.Println("error writing response:", sendError)
-				}
-			} else {
-				w.WriteHeader(http.StatusInternalServerError)
-				if _, sendError := w.Write([]byte(err.Error())); sendError != nil {
-					log.Println("error writing response:", sendError)
-				}
-			}
-			return
-		}
+// If the HTTPResponse also implements HTTPHeaders, those headers are set first.
+// Errors are unwrapped to find an HTTPResponse, as errors.As does. If the error
+// does not wrap an HTTPResponse, a 500 status code will be returned with the
+// body being exactly the error's string.
+// Response structs can use `http` tags to set the status code, headers and
+// cookies of the response, in which case those fields are left out of the
+// JSON body:
+//
+//	type Response struct {
+//		Status  int          `http:"status"`
+//		ETag    string       `http:"header=ETag"`
+//		Session *http.Cookie `http:"cookie=session"`
+//		Pet     Pet          `json:"pet"`
+//	}
+//
+// Readers, files and FileResponses are sent as they are, going through
+// http.ServeContent when they can seek so that Range and conditional requests
+// are supported.
+// Channels, iter.Seq and iter.Seq2 with an error as their second value are
+// streamed to the client as newline-delimited JSON, or as Server-Sent Events
+// when the Accept header asks for text/event-stream.
+//
+// The behavior of the writer can be changed with ResponseWriterOptions.
+func StandardResponseWriter(opts ...ResponseWriterOption) ResponseWriter {
+	config := &responseConfig{logger: slog.Default()}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config.write
+}
+
+// ResponseWriterOption configures the StandardResponseWriter.
+type ResponseWriterOption func(*responseConfig)
+
+// responseConfig holds the configuration of the StandardResponseWriter.
+type responseConfig struct {
+	// codecs is the registry used to negotiate the encoding of the
+	// responses. Responses are always encoded as JSON when it is nil.
+	codecs *CodecRegistry
+
+	// problems is set when errors are rendered as Problem Details.
+	problems bool
+
+	// errors maps the errors without HTTP information to responses.
+	errors *ErrorRegistry
+
+	// etags is set when responses are sent with an ETag.
+	etags bool
+
+	// logger is the logger of the errors that happen while writing the
+	// responses.
+	logger *slog.Logger
+
+	// cookieKeys holds the keys of the cookie fields tagged as signed or
+	// encrypted.
+	cookieKeys CookieKeys
+}
+
+// WithCodecs makes the writer encode responses with the codec of the
+// registry that best matches the Accept header of the request. If none of
+// the codecs are acceptable, a 406 Not Acceptable is sent back. A nil
+// registry uses the DefaultCodecs.
+func WithCodecs(registry *CodecRegistry) ResponseWriterOption {
+	if registry == nil {
+		registry = DefaultCodecs()
+	}
+	return func(config *responseConfig) {
+		config.codecs = registry
+	}
+}
+
+// WithErrors makes the writer send the responses that the registry maps
+// errors to.
+func WithErrors(registry *ErrorRegistry) ResponseWriterOption {
+	return func(config *responseConfig) {
+		config.errors = registry
+	}
+}
+
+// WithETags makes the writer send encoded responses with a strong ETag, and
+// answer GET and HEAD requests whose If-None-Match header matches it with a
+// 304 Not Modified and no body. The ETag is the hash of the encoded body,
+// unless the response object implements ETagger or already set the ETag
+// header through its `http` tags.
+func WithETags() ResponseWriterOption {
+	return func(config *responseConfig) {
+		config.etags = true
+	}
+}
+
+// WithCookieKeys sets the keys used to sign and encrypt the cookie fields of
+// the responses tagged with the `signed` and `encrypted` options, e.g:
+// `http:"cookie=session,signed"`. Responses with such cookies fail with a
+// 500 when no key is configured.
+func WithCookieKeys(keys CookieKeys) ResponseWriterOption {
+	return func(config *responseConfig) {
+		config.cookieKeys = keys
+	}
+}
+
+// WithLogger sets the logger of the errors that happen while writing the
+// responses. It defaults to slog.Default().
+func WithLogger(logger *slog.Logger) ResponseWriterOption {
+	return func(config *responseConfig) {
+		config.logger = logger
+	}
+}
+
+// WithProblemDetails makes the writer render every error as an RFC 9457
+// Problem Details object. See ProblemFromError for how errors are mapped.
+func WithProblemDetails() ResponseWriterOption {
+	return func(config *responseConfig) {
+		config.problems = true
+	}
+}
+
+func (config *responseConfig) write(w http.ResponseWriter, req *http.Request, res any, err error) {
+	if err != nil {
+		config.writeError(w, req, err)
+		return
+	}
+
+	if res == nil {
+		return
+	}
 
-		if res == nil {
-			return
+	if cast, ok := res.(HTTPResponse); ok {
+		config.writeHTTPResponse(w, req, cast)
+		return
+	} else if file, ok := fileResponse(res); ok {
+		config.writeFile(w, req, file)
+		return
+	} else if isStream(res) {
+		config.writeStream(w, req, res)
+		return
+	}
+
+	if config.codecs == nil {
+		config.writeEncoded(w, req, res, JSONCodec())
+		return
+	}
+
+	w.Header().Add("Vary", "Accept")
+	codec, found := config.codecs.Negotiate(req.Header.Get("Accept"), res)
+	if !found {
+		available := strings.Join(config.codecs.ContentTypes(), ", ")
+		config.writeError(w, req, NewHTTPError(http.StatusNotAcceptable, "Not Acceptable. Available: %s.", available))
+		return
+	}
+	config.writeEncoded(w, req, res, codec)
+}
+
+// writeError sends the error to the client. Errors that do not wrap an
+// HTTPResponse are sent as a 500 with the error's string as the body.
+// The ID of the request, if any, is sent in the X-Request-ID header and as
+// the request_id member of Problem Details.
+func (config *responseConfig) writeError(w http.ResponseWriter, req *http.Request, err error) {
+	requestID, hasRequestID := RequestIDFromContext(req.Context())
+	if hasRequestID && w.Header().Get(RequestIDHeader) == "" {
+		w.Header().Set(RequestIDHeader, string(requestID))
+	}
+
+	if config.errors != nil {
+		if res, found := config.errors.Lookup(err); found {
+			err = responseError{HTTPResponse: res, err: err}
 		}
+	}
 
-		if cast, ok := res.(HTTPResponse); ok {
-			code := cast.StatusCode()
-			if code != 0 {
-				w.WriteHeader(cast.StatusCode())
+	if config.problems {
+		var cast HTTPResponse
+		if !errors.As(err, &cast) || cast.StatusCode() != 0 {
+			problem := *ProblemFromError(err)
+			if hasRequestID {
+				problem.Extensions = maps.Clone(problem.Extensions)
+				problem.With("request_id", requestID)
 			}
-			if sendError := cast.WriteBody(w); sendError != nil {
-				log.Println("error writing response:", sendError)
-			}
-			return
+			err = &problem
 		}
+	}
+
+	var cast HTTPResponse
+	if errors.As(err, &cast) {
+		config.writeHTTPResponse(w, req, cast)
+		return
+	}
+
+	w.WriteHeader(http.StatusInternalServerError)
+	if _, sendError := w.Write([]byte(err.Error())); sendError != nil {
+		config.logger.ErrorContext(req.Context(), "error writing response", "error", sendError)
+	}
+}
+
+// responseError is the error sent in place of an error found in the
+// ErrorRegistry of the writer.
+type responseError struct {
+	HTTPResponse
+	err error
+}
 
-		w.WriteHeader(http.StatusOK)
-		w.Header().Set("Content-Type", "application/json")
-		encoder := json.NewEncoder(w)
-		encoder.SetEscapeHTML(false)
-		if sendError := encoder.Encode(res); sendError != nil {
-			log.Println("Error writing response:", sendError)
+func (err responseError) Error() string { return err.err.Error() }
+
+func (err responseError) Unwrap() error { return err.err }
+
+func (err responseError) Headers() http.Header {
+	if cast, ok := err.HTTPResponse.(HTTPHeaders); ok {
+		return cast.Headers()
+	}
+	return nil
+}
+
+// writeEncoded sends the response object to the client using the codec,
+// along with the metadata read from its `http` tags. The object is encoded
+// before anything is sent so that encoding failures become a 500.
+func (config *responseConfig) writeEncoded(w http.ResponseWriter, req *http.Request, res any, codec Codec) {
+	meta, _, err := readResponseMeta(res)
+	if err == nil {
+		err = meta.protectCookies(config.cookieKeys)
+	}
+	if err != nil {
+		config.logger.ErrorContext(req.Context(), "error reading response metadata", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	if err := codec.Encode(buf, res); err != nil {
+		config.logger.ErrorContext(req.Context(), "error encoding response", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusOK
+	if meta.status != 0 {
+		status = meta.status
+	}
+	meta.write(w)
+	if config.etags && status == http.StatusOK && setETag(w, req, res, buf.Bytes()) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", codec.ContentType())
+	w.WriteHeader(status)
+	if _, sendError := w.Write(buf.Bytes()); sendError != nil {
+		config.logger.ErrorContext(req.Context(), "error writing response", "error", sendError)
+	}
+}
+
+// writeHTTPResponse sends the headers, status code and body of the
+// HTTPResponse to the client.
+func (config *responseConfig) writeHTTPResponse(w http.ResponseWriter, req *http.Request, res HTTPResponse) {
+	if cast, ok := res.(HTTPHeaders); ok {
+		for key, vals := range cast.Headers() {
+			for _, val := range vals {
+				w.Header().Add(key, val)
+			}
 		}
 	}
+
+	code := res.StatusCode()
+	if code != 0 {
+		w.WriteHeader(code)
+	}
+	if sendError := res.WriteBody(w); sendError != nil {
+		config.logger.ErrorContext(req.Context(), "error writing response", "error", sendError)
+	}
 }
 
 // NewStandardWrapper returns a new wrapper using the StandardRequestReader and the
 // StandardResponseWriter.
 func NewStandardWrapper() Wrapper {
-	constructor := StandardRequestReader()
 	responseWriter := StandardResponseWriter()
 	return New().
-		WithRequestReader(constructor).
+		WithDecoder(NewDecoder()).
 		Finally(responseWriter)
 }
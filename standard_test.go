@@ -1,6 +1,7 @@
 package httpwrap
 
 import (
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -52,6 +53,20 @@ func TestStandardWrapper(t *testing.T) {
 		require.Equal(t, http.StatusForbidden, rw.Result().StatusCode)
 	})
 
+	t.Run("wrapped error", func(t *testing.T) {
+		errNotFound := NewHTTPError(http.StatusNotFound, "Not found.")
+		handler := NewStandardWrapper().Wrap(func() error {
+			return fmt.Errorf("loading pet: %w", errNotFound)
+		})
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/endpoint", nil)
+		handler.ServeHTTP(rw, req)
+		statusCode, body := readResponseRecorder(t, rw)
+		require.Equal(t, http.StatusNotFound, statusCode)
+		require.Equal(t, "Not found.", body)
+	})
+
 	t.Run("no middleware", func(t *testing.T) {
 		wrapper := NewStandardWrapper()
 		handler := wrapper.Wrap(func(p1 header, p2 query) error {
@@ -84,6 +99,7 @@ func TestStandardWrapper(t *testing.T) {
 		statusCode, body := readResponseRecorder(t, rw)
 		require.Equal(t, http.StatusOK, statusCode)
 		require.Equal(t, `{"value":42}`, body)
+		require.Equal(t, "application/json", rw.Result().Header.Get("Content-Type"))
 	})
 
 	t.Run("with middleware", func(t *testing.T) {
@@ -162,6 +178,32 @@ func TestStandardWrapper(t *testing.T) {
 	})
 }
 
+func TestStandardWrapperCredentials(t *testing.T) {
+	type credentials struct {
+		Token string `http:"bearer"`
+	}
+
+	handler := NewStandardWrapper().Wrap(func(creds credentials) error {
+		require.Equal(t, "abc", creds.Token)
+		return nil
+	})
+
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/endpoint", nil)
+	req.Header.Set("Authorization", "Bearer abc")
+	handler.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+
+	rw = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/endpoint", nil)
+	req.Header.Set("Authorization", "Basic abc")
+	handler.ServeHTTP(rw, req)
+	statusCode, body := readResponseRecorder(t, rw)
+	require.Equal(t, http.StatusUnauthorized, statusCode)
+	require.Equal(t, "Malformed credentials.", body)
+	require.Contains(t, rw.Result().Header.Get("WWW-Authenticate"), "Bearer")
+}
+
 func readResponseRecorder(t *testing.T, rw *httptest.ResponseRecorder) (int, string) {
 	result := rw.Result()
 	body, err := io.ReadAll(result.Body)
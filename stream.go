@@ -0,0 +1,157 @@
+package httpwrap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"reflect"
+)
+
+// The media types that streams can be sent as.
+const (
+	contentTypeNDJSON = "application/x-ndjson"
+	contentTypeSSE    = "text/event-stream"
+)
+
+// isStream returns whether the response object is a stream of items, i.e:
+// a receiving channel, an iter.Seq[T] or an iter.Seq2[T, error].
+func isStream(res any) bool {
+	return isStreamType(reflect.TypeOf(res))
+}
+
+// isStreamType returns whether values of the type are streams of items.
+func isStreamType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Chan:
+		return t.ChanDir()&reflect.RecvDir != 0
+	case reflect.Func:
+		if t.NumIn() != 1 || t.NumOut() != 0 {
+			return false
+		}
+		yield := t.In(0)
+		if yield.Kind() != reflect.Func || yield.NumOut() != 1 || yield.Out(0).Kind() != reflect.Bool {
+			return false
+		}
+		return yield.NumIn() == 1 || (yield.NumIn() == 2 && isError(yield.In(1)))
+	}
+	return false
+}
+
+// writeStream sends the items of the stream to the client as they come,
+// flushing the response after each one. Items are sent as Server-Sent Events
+// if the client prefers text/event-stream, and as newline-delimited JSON
+// otherwise. Streaming stops when the stream ends, when an iter.Seq2 yields
+// an error, which is sent as the last item, or when the request context is
+// done.
+func (config *responseConfig) writeStream(w http.ResponseWriter, req *http.Request, res any) {
+	ranges := parseAccept(req.Header.Get("Accept"))
+	stream := &streamWriter{
+		w:          w,
+		controller: http.NewResponseController(w),
+		ctx:        req.Context(),
+		logger:     config.logger,
+		sse:        acceptQuality(ranges, contentTypeSSE) > acceptQuality(ranges, contentTypeNDJSON),
+	}
+
+	if stream.sse {
+		w.Header().Set("Content-Type", contentTypeSSE)
+		w.Header().Set("Cache-Control", "no-cache")
+	} else {
+		w.Header().Set("Content-Type", contentTypeNDJSON)
+	}
+	w.WriteHeader(http.StatusOK)
+	stream.flush()
+
+	v := reflect.ValueOf(res)
+	if v.IsNil() {
+		return
+	} else if v.Kind() == reflect.Chan {
+		stream.fromChan(req, v)
+	} else {
+		stream.fromSeq(req, v)
+	}
+}
+
+type streamWriter struct {
+	w          http.ResponseWriter
+	controller *http.ResponseController
+	ctx        context.Context
+	logger     *slog.Logger
+	sse        bool
+}
+
+func (stream *streamWriter) fromChan(req *http.Request, ch reflect.Value) {
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: ch},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(req.Context().Done())},
+	}
+	for {
+		chosen, item, ok := reflect.Select(cases)
+		if chosen != 0 || !ok {
+			return
+		} else if err := stream.write("", item.Interface()); err != nil {
+			stream.logger.ErrorContext(stream.ctx, "error writing stream", "error", err)
+			return
+		}
+	}
+}
+
+func (stream *streamWriter) fromSeq(req *http.Request, seq reflect.Value) {
+	yieldType := seq.Type().In(0)
+	yield := reflect.MakeFunc(yieldType, func(args []reflect.Value) []reflect.Value {
+		if req.Context().Err() != nil {
+			return []reflect.Value{reflect.ValueOf(false)}
+		}
+
+		// Errors of value types are reported unless they are zero values.
+		if len(args) == 2 && !args[1].IsZero() {
+			message := args[1].Interface().(error).Error()
+			if err := stream.write("error", map[string]string{"error": message}); err != nil {
+				stream.logger.ErrorContext(stream.ctx, "error writing stream", "error", err)
+			}
+			return []reflect.Value{reflect.ValueOf(false)}
+		} else if err := stream.write("", args[0].Interface()); err != nil {
+			stream.logger.ErrorContext(stream.ctx, "error writing stream", "error", err)
+			return []reflect.Value{reflect.ValueOf(false)}
+		}
+		return []reflect.Value{reflect.ValueOf(true)}
+	})
+	seq.Call([]reflect.Value{yield})
+}
+
+// write sends a single item of the stream. The event name is only used by
+// Server-Sent Events.
+func (stream *streamWriter) write(event string, item any) error {
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(item); err != nil {
+		return err
+	}
+
+	if stream.sse {
+		data := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+		buf = &bytes.Buffer{}
+		if event != "" {
+			buf.WriteString("event: " + event + "\n")
+		}
+		buf.WriteString("data: ")
+		buf.Write(data)
+		buf.WriteString("\n\n")
+	}
+
+	if _, err := stream.w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	stream.flush()
+	return nil
+}
+
+func (stream *streamWriter) flush() {
+	if err := stream.controller.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		stream.logger.ErrorContext(stream.ctx, "error flushing stream", "error", err)
+	}
+}
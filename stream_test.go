@@ -0,0 +1,115 @@
+package httpwrap
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreams(t *testing.T) {
+	wrapper := NewStandardWrapper()
+
+	t.Run("channel as ndjson", func(t *testing.T) {
+		handler := wrapper.Wrap(func() <-chan typedResponse {
+			ch := make(chan typedResponse, 2)
+			ch <- typedResponse{Value: 1}
+			ch <- typedResponse{Value: 2}
+			close(ch)
+			return ch
+		})
+
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, httptest.NewRequest("GET", "/progress", nil))
+		statusCode, body := readResponseRecorder(t, rw)
+		require.Equal(t, http.StatusOK, statusCode)
+		require.Equal(t, "application/x-ndjson", rw.Result().Header.Get("Content-Type"))
+		require.Equal(t, "{\"value\":1}\n{\"value\":2}", body)
+		require.True(t, rw.Flushed)
+	})
+
+	t.Run("seq as sse", func(t *testing.T) {
+		handler := wrapper.Wrap(func() iter.Seq[int] {
+			return func(yield func(int) bool) {
+				for i := 0; i < 3; i++ {
+					if !yield(i) {
+						return
+					}
+				}
+			}
+		})
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/logs", nil)
+		req.Header.Set("Accept", "text/event-stream")
+		handler.ServeHTTP(rw, req)
+		statusCode, body := readResponseRecorder(t, rw)
+		require.Equal(t, http.StatusOK, statusCode)
+		require.Equal(t, "text/event-stream", rw.Result().Header.Get("Content-Type"))
+		require.Equal(t, "data: 0\n\ndata: 1\n\ndata: 2", body)
+	})
+
+	t.Run("seq2 with error", func(t *testing.T) {
+		handler := wrapper.Wrap(func() iter.Seq2[string, error] {
+			return func(yield func(string, error) bool) {
+				if !yield("line1", nil) {
+					return
+				}
+				if !yield("", fmt.Errorf("tail failed")) {
+					return
+				}
+				require.FailNow(t, "should stop after the error")
+			}
+		})
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/logs", nil)
+		req.Header.Set("Accept", "text/event-stream")
+		handler.ServeHTTP(rw, req)
+		_, body := readResponseRecorder(t, rw)
+		require.Equal(t, "data: \"line1\"\n\nevent: error\ndata: {\"error\":\"tail failed\"}", body)
+	})
+
+	t.Run("seq2 with value error", func(t *testing.T) {
+		handler := wrapper.Wrap(func() iter.Seq2[int, streamValueError] {
+			return func(yield func(int, streamValueError) bool) {
+				if !yield(1, streamValueError{}) {
+					return
+				}
+				yield(0, streamValueError{msg: "tail failed"})
+			}
+		})
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/logs", nil)
+		req.Header.Set("Accept", "text/event-stream")
+		handler.ServeHTTP(rw, req)
+		_, body := readResponseRecorder(t, rw)
+		require.Equal(t, "data: 1\n\nevent: error\ndata: {\"error\":\"tail failed\"}", body)
+	})
+
+	t.Run("stops on cancelled context", func(t *testing.T) {
+		ch := make(chan int)
+		handler := wrapper.Wrap(func() <-chan int { return ch })
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			ch <- 1
+			cancel()
+		}()
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/progress", nil).WithContext(ctx)
+		handler.ServeHTTP(rw, req)
+		_, body := readResponseRecorder(t, rw)
+		require.Equal(t, "1", body)
+	})
+}
+
+type streamValueError struct{ msg string }
+
+func (err streamValueError) Error() string { return err.msg }
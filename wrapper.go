@@ -1,6 +1,8 @@
 package httpwrap
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"reflect"
 )
@@ -11,6 +13,11 @@ type Wrapper struct {
 	befores   []beforeFn
 	after     *afterFn
 	construct RequestReader
+	overrides overrides
+
+	// decoder is the decoder of the RequestReader, when it was set with
+	// WithDecoder.
+	decoder *Decoder
 }
 
 // New creates a new Wrapper object. This wrapper object will not interact in any way
@@ -24,6 +31,17 @@ func New() Wrapper {
 // WithRequestReader returns a new wrapper with the given RequestReader function.
 func (w Wrapper) WithRequestReader(cons RequestReader) Wrapper {
 	w.construct = cons
+	w.decoder = nil
+	return w
+}
+
+// WithDecoder returns a new wrapper that reads requests with the decoder,
+// like WithRequestReader(d.RequestReader()) does. The generated adapters
+// also decode their inputs with it, so that they read requests exactly like
+// the handlers they replace.
+func (w Wrapper) WithDecoder(d *Decoder) Wrapper {
+	w.construct = d.RequestReader()
+	w.decoder = d
 	return w
 }
 
@@ -43,6 +61,63 @@ func (w Wrapper) Before(fns ...any) Wrapper {
 	return w
 }
 
+// Override returns a new wrapper where the before old is replaced by the
+// before new, at the same position in the chain. It panics if old is not a
+// before of the wrapper.
+//
+// Functions are compared by their code, not by their identity: closures
+// created by the same function literal are all equal, and so are the method
+// values of the same method on different receivers. Every before of the
+// chain sharing the code of old is replaced by new.
+func (w Wrapper) Override(old, new any) Wrapper {
+	helper, err := newBefore(new)
+	if err != nil {
+		panic(err)
+	}
+
+	target := reflect.ValueOf(old)
+	if target.Kind() != reflect.Func {
+		panic(fmt.Errorf("override target must be a function, got %T", old))
+	}
+
+	befores := make([]beforeFn, len(w.befores))
+	copy(befores, w.befores)
+	found := false
+	for i, before := range befores {
+		if before.val.Pointer() == target.Pointer() {
+			befores[i], found = helper, true
+		}
+	}
+	if !found {
+		panic(fmt.Errorf("override target %T is not a before of the wrapper", old))
+	}
+	w.befores = befores
+	return w
+}
+
+// WithOverride returns a new wrapper that supplies val to every function
+// taking a T, or an interface implemented by T, in place of the values of
+// that type returned by the befores or constructed from the request. The
+// befores still run, but their outputs of type T are ignored.
+//
+//	wrapper = httpwrap.WithOverride[*sql.DB](wrapper, fakeDB)
+func WithOverride[T any](w Wrapper, val T) Wrapper {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	v := reflect.New(t).Elem()
+	if any(val) != nil {
+		v.Set(reflect.ValueOf(val))
+	}
+
+	overrides := make(overrides, 0, len(w.overrides)+1)
+	for _, p := range w.overrides {
+		if p.t != t {
+			overrides = append(overrides, p)
+		}
+	}
+	w.overrides = append(overrides, param{t: t, v: v, i: val})
+	return w
+}
+
 // Finally sets the last function that will execute during a request. This function gets
 // invoked with the response object and the possible error returned from the main
 // endpoint function.
@@ -68,6 +143,50 @@ func (w Wrapper) Wrap(fn any) http.Handler {
 	}
 }
 
+// Invoke runs the befores and the main function outside of any http
+// request, e.g: from a CLI or a queue consumer. The inputs and the context
+// are provided to every stage as if a before had returned them, and the
+// parameters that no stage provides are zero values since there is no
+// request to read them from. The result and the error of the main function
+// are returned, or the error of the first before that failed.
+//
+// The Finally function then runs with a request carrying the context, a
+// response writer that discards the response and the error of the
+// invocation, so that it can log or measure it like it does for http
+// requests. It is given no response, since writing the result would consume
+// it before it is returned, e.g: readers and streams. An error returned by
+// the Finally function is returned when the other stages succeeded.
+//
+//	res, err := wrapper.Invoke(ctx, AdoptPet, APICredentials{Key: key}, AdoptParams{Name: "rex"})
+func (w Wrapper) Invoke(ctx context.Context, fn any, inputs ...any) (any, error) {
+	main, err := newMain(fn)
+	if err != nil {
+		return nil, err
+	} else if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	run := newInvokeCtx(ctx, inputs)
+	run.overrides = w.overrides
+	h := wrappedHttpHandler{Wrapper: w, main: main}
+	var res any
+	if err = h.serveBefores(run); err == nil {
+		provided := len(run.resultSlice)
+		res = main.run(run)
+		err = run.lastError(provided)
+	}
+
+	if w.after != nil {
+		run.detach(ctx)
+		provided := len(run.resultSlice)
+		w.after.run(run)
+		if err == nil {
+			err = run.lastError(provided)
+		}
+	}
+	return res, err
+}
+
 // wrappedHttpHandler is a Wrapper that implements `http.Handler`.
 type wrappedHttpHandler struct {
 	Wrapper
@@ -77,6 +196,8 @@ type wrappedHttpHandler struct {
 // ServeHTTP implements `http.Handler`.
 func (h wrappedHttpHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	ctx := newRunCtx(rw, req, h.construct)
+	ctx.overrides = h.overrides
+	ctx.decoder = h.decoder
 	err := h.serveBefores(ctx)
 	if err == nil {
 		ctx.response = reflect.ValueOf(h.main.run(ctx))
@@ -1,9 +1,13 @@
 package httpwrap
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"iter"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
 
@@ -241,3 +245,194 @@ func TestWrapper(t *testing.T) {
 		require.Equal(t, http.StatusCreated, rw.Result().StatusCode)
 	})
 }
+
+func TestWrapperInvoke(t *testing.T) {
+	type credentials struct{ Key string }
+	type user struct{ Name string }
+	type adoptParams struct{ Pet string }
+	type sourceKey struct{}
+
+	authenticate := func(creds credentials) (user, error) {
+		if creds.Key != "secret" {
+			return user{}, NewHTTPError(http.StatusUnauthorized, "Unauthorized.")
+		}
+		return user{Name: "alice"}, nil
+	}
+	wrapper := NewStandardWrapper().Before(authenticate)
+	adopt := func(ctx context.Context, u user, params adoptParams) (string, error) {
+		if params.Pet == "" {
+			return "", fmt.Errorf("no pet given")
+		}
+		return fmt.Sprintf("%s adopted %s (%v)", u.Name, params.Pet, ctx.Value(sourceKey{})), nil
+	}
+	ctx := context.WithValue(context.Background(), sourceKey{}, "cron")
+
+	t.Run("success", func(t *testing.T) {
+		res, err := wrapper.Invoke(ctx, adopt, credentials{Key: "secret"}, adoptParams{Pet: "rex"})
+		require.NoError(t, err)
+		require.Equal(t, "alice adopted rex (cron)", res)
+	})
+
+	t.Run("before error", func(t *testing.T) {
+		res, err := wrapper.Invoke(ctx, adopt, credentials{Key: "wrong"}, adoptParams{Pet: "rex"})
+		require.Nil(t, res)
+		require.Error(t, err)
+		require.Equal(t, http.StatusUnauthorized, err.(HTTPError).StatusCode())
+	})
+
+	t.Run("main error", func(t *testing.T) {
+		_, err := wrapper.Invoke(ctx, adopt, credentials{Key: "secret"})
+		require.EqualError(t, err, "no pet given")
+	})
+
+	t.Run("invalid main", func(t *testing.T) {
+		_, err := wrapper.Invoke(ctx, func(any) {})
+		require.Error(t, err)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := wrapper.Invoke(canceled, adopt, credentials{Key: "secret"}, adoptParams{Pet: "rex"})
+		require.Equal(t, context.Canceled, err)
+	})
+
+	t.Run("finally", func(t *testing.T) {
+		var seen []string
+		logged := func(req *http.Request, rw http.ResponseWriter, info ResponseInfo, res any, err error) error {
+			StandardResponseWriter()(rw, req, res, err)
+			source := req.Context().Value(sourceKey{})
+			seen = append(seen, fmt.Sprintf("%v|%v|%d|%v", res, err, info.Status(), source))
+			if source == "broken" {
+				return fmt.Errorf("could not log")
+			}
+			return nil
+		}
+		wrapper := wrapper.Finally(logged)
+
+		res, err := wrapper.Invoke(ctx, adopt, credentials{Key: "secret"}, adoptParams{Pet: "rex"})
+		require.NoError(t, err)
+		require.Equal(t, "alice adopted rex (cron)", res)
+
+		_, err = wrapper.Invoke(ctx, adopt, credentials{Key: "wrong"})
+		require.Equal(t, http.StatusUnauthorized, err.(HTTPError).StatusCode())
+
+		broken := context.WithValue(ctx, sourceKey{}, "broken")
+		res, err = wrapper.Invoke(broken, adopt, credentials{Key: "secret"}, adoptParams{Pet: "rex"})
+		require.EqualError(t, err, "could not log")
+		require.Equal(t, "alice adopted rex (broken)", res)
+
+		require.Equal(t, []string{
+			"<nil>|<nil>|0|cron",
+			"<nil>|http error: 401: Unauthorized.|401|cron",
+			"<nil>|<nil>|0|broken",
+		}, seen)
+	})
+
+	t.Run("results are not written", func(t *testing.T) {
+		wrapper := NewStandardWrapper()
+
+		res, err := wrapper.Invoke(ctx, func() io.Reader { return strings.NewReader("hello") })
+		require.NoError(t, err)
+		body, err := io.ReadAll(res.(io.Reader))
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(body))
+
+		res, err = wrapper.Invoke(ctx, func() <-chan int {
+			ch := make(chan int, 3)
+			ch <- 1
+			ch <- 2
+			ch <- 3
+			close(ch)
+			return ch
+		})
+		require.NoError(t, err)
+		require.Len(t, res.(<-chan int), 3)
+
+		pulled := 0
+		res, err = wrapper.Invoke(ctx, func() iter.Seq[int] {
+			return func(yield func(int) bool) {
+				pulled++
+				yield(1)
+			}
+		})
+		require.NoError(t, err)
+		require.Equal(t, 0, pulled)
+		require.Equal(t, []int{1}, slices.Collect(res.(iter.Seq[int])))
+	})
+}
+
+type overrideDB struct{ name string }
+
+func (db *overrideDB) Name() string { return db.name }
+
+type overrideStore interface{ Name() string }
+
+func connectDB() *overrideDB { return &overrideDB{name: "real"} }
+
+func authenticateUser(req *http.Request) (string, error) {
+	if req.Header.Get("Authorization") == "" {
+		return "", NewHTTPError(http.StatusUnauthorized, "Unauthorized.")
+	}
+	return req.Header.Get("Authorization"), nil
+}
+
+func TestWrapperOverride(t *testing.T) {
+	wrapper := NewStandardWrapper().Before(connectDB, authenticateUser)
+	main := func(db *overrideDB, user string) string { return db.name + ":" + user }
+
+	serve := func(wrapper Wrapper, authorization string) (int, string) {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/endpoint", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		wrapper.Wrap(main).ServeHTTP(rw, req)
+		return readResponseRecorder(t, rw)
+	}
+
+	statusCode, body := serve(wrapper, "alice")
+	require.Equal(t, http.StatusOK, statusCode)
+	require.Equal(t, `"real:alice"`, body)
+
+	t.Run("before", func(t *testing.T) {
+		stubbed := wrapper.Override(authenticateUser, func() (string, error) { return "bob", nil })
+		statusCode, body := serve(stubbed, "")
+		require.Equal(t, http.StatusOK, statusCode)
+		require.Equal(t, `"real:bob"`, body)
+
+		statusCode, _ = serve(wrapper, "")
+		require.Equal(t, http.StatusUnauthorized, statusCode, "the original wrapper is unchanged")
+
+		require.Panics(t, func() { wrapper.Override(connectDB, func(any) {}) })
+		require.Panics(t, func() { wrapper.Override(main, connectDB) })
+	})
+
+	t.Run("type", func(t *testing.T) {
+		faked := WithOverride(wrapper, &overrideDB{name: "fake"})
+		statusCode, body := serve(faked, "alice")
+		require.Equal(t, http.StatusOK, statusCode)
+		require.Equal(t, `"fake:alice"`, body)
+
+		res, err := WithOverride(New().Before(connectDB), &overrideDB{name: "fake"}).
+			Invoke(context.Background(), func(db *overrideDB) string { return db.name })
+		require.NoError(t, err)
+		require.Equal(t, "fake", res)
+
+		statusCode, body = serve(wrapper, "alice")
+		require.Equal(t, http.StatusOK, statusCode)
+		require.Equal(t, `"real:alice"`, body)
+	})
+
+	t.Run("interface", func(t *testing.T) {
+		byInterface := func(store overrideStore) string { return store.Name() }
+		res, err := WithOverride(New().Before(connectDB), &overrideDB{name: "fake"}).
+			Invoke(context.Background(), byInterface)
+		require.NoError(t, err)
+		require.Equal(t, "fake", res)
+
+		res, err = New().Before(connectDB).Invoke(context.Background(), byInterface)
+		require.NoError(t, err)
+		require.Equal(t, "real", res)
+	})
+}